@@ -0,0 +1,54 @@
+package call
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// ParseArgs converts string arguments, as typed on a command line, into the Go
+// values the ABI packer expects for the inputs of method. Arrays and tuples are
+// written as JSON, and so may be string arguments holding commas.
+func ParseArgs(method abi.Method, args []string) ([]interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg
+		if i < len(method.Inputs) && method.Inputs[i].Type.T == abi.StringTy && strings.HasPrefix(arg, `"`) {
+			var text string
+			if json.Unmarshal([]byte(arg), &text) == nil {
+				values[i] = text
+			}
+		}
+	}
+	return CoerceArgs(method, values)
+}
+
+// SplitArgs splits comma separated arguments, keeping commas inside JSON arrays,
+// objects and strings so array and tuple arguments can be passed as JSON.
+func SplitArgs(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var args []string
+	depth, quoted, escaped, start := 0, false, false, 0
+	for i, char := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && char == '\\':
+			escaped = true
+		case char == '"':
+			quoted = !quoted
+		case quoted:
+		case char == '[' || char == '{':
+			depth++
+		case char == ']' || char == '}':
+			depth--
+		case char == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(args, strings.TrimSpace(value[start:]))
+}
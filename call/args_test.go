@@ -0,0 +1,43 @@
+package call
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"1", []string{"1"}},
+		{"0x01, 2 ,3", []string{"0x01", "2", "3"}},
+		{`[1,2,3],4`, []string{"[1,2,3]", "4"}},
+		{`[[1,2],[3]],[]`, []string{"[[1,2],[3]]", "[]"}},
+		{`["0x01",[2,"a,b"]],5`, []string{`["0x01",[2,"a,b"]]`, "5"}},
+		{`{"amount":1,"to":"0x02"},[{"a":[1,2]},{"a":[]}]`, []string{`{"amount":1,"to":"0x02"}`, `[{"a":[1,2]},{"a":[]}]`}},
+		{`"a,b",c`, []string{`"a,b"`, "c"}},
+		{`"say \"x,y\"",z`, []string{`"say \"x,y\""`, "z"}},
+		{`"ends with \\",z`, []string{`"ends with \\"`, "z"}},
+		{`"[",1`, []string{`"["`, "1"}},
+		{"1,,2", []string{"1", "", "2"}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, SplitArgs(test.value), test.value)
+	}
+}
+
+func TestParseArgs_Split(t *testing.T) {
+	contract := NewContractBuilder().AddMethod("swap((address,uint256)[],uint256[],string)(bool)")
+	method := contract.Abi().Methods["swap"]
+	args, err := ParseArgs(method, SplitArgs(`[["0x0000000000000000000000000000000000000002",1],["0x0000000000000000000000000000000000000003","0x10"]],[1,2],"a,b"`))
+	assert.NoError(t, err)
+	assert.Len(t, args, 3)
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, args[1])
+	assert.Equal(t, "a,b", args[2])
+	_, err = contract.Abi().Pack("swap", args...)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,314 @@
+package call
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ArgumentError reports which argument of a method could not be coerced. Path
+// locates the offending element inside arrays and tuples, e.g. "[2].amount".
+type ArgumentError struct {
+	Method string
+	Index  int
+	Path   string
+	Type   string
+	Err    error
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("%s argument %d%s (%s): %v", e.Method, e.Index, e.Path, e.Type, e.Err)
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// CoerceArgs converts untyped arguments into the exact Go types the ABI packer
+// expects for the inputs of method. It accepts strings (decimal, 0x hex, JSON
+// arrays and objects), JSON numbers, booleans, Go integers, slices for arrays
+// and tuples, and maps keyed by component name for tuples. Values that already
+// have the expected type are passed through.
+func CoerceArgs(method abi.Method, args []interface{}) ([]interface{}, error) {
+	if len(args) != len(method.Inputs) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", method.Name, len(method.Inputs), len(args))
+	}
+	res := make([]interface{}, len(args))
+	for i, input := range method.Inputs {
+		value, err := coerce(input.Type, args[i], "")
+		if err != nil {
+			var argErr *ArgumentError
+			if errors.As(err, &argErr) {
+				argErr.Method, argErr.Index = method.Name, i
+				return nil, argErr
+			}
+			return nil, &ArgumentError{Method: method.Name, Index: i, Type: input.Type.String(), Err: err}
+		}
+		res[i] = value.Interface()
+	}
+	return res, nil
+}
+
+func coerce(argType abi.Type, arg interface{}, path string) (reflect.Value, error) {
+	goType := argType.GetType()
+	if arg != nil && reflect.TypeOf(arg) == goType {
+		return reflect.ValueOf(arg), nil
+	}
+	var (
+		value reflect.Value
+		err   error
+	)
+	switch argType.T {
+	case abi.AddressTy:
+		value, err = coerceAddress(arg)
+	case abi.BoolTy:
+		value, err = coerceBool(arg)
+	case abi.StringTy:
+		text, ok := arg.(string)
+		if !ok {
+			err = fmt.Errorf("expected a string, got %T", arg)
+		}
+		value = reflect.ValueOf(text)
+	case abi.BytesTy:
+		var data []byte
+		data, err = coerceBytes(arg)
+		value = reflect.ValueOf(data)
+	case abi.FixedBytesTy:
+		value, err = coerceFixedBytes(argType, arg)
+	case abi.IntTy, abi.UintTy:
+		value, err = coerceInteger(argType, arg)
+	case abi.SliceTy, abi.ArrayTy:
+		return coerceList(argType, arg, path)
+	case abi.TupleTy:
+		return coerceTuple(argType, arg, path)
+	default:
+		err = fmt.Errorf("unsupported type")
+	}
+	if err != nil {
+		return reflect.Value{}, &ArgumentError{Path: path, Type: argType.String(), Err: err}
+	}
+	return value, nil
+}
+
+func coerceAddress(arg interface{}) (reflect.Value, error) {
+	text, ok := arg.(string)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("expected a hex address, got %T", arg)
+	}
+	if !common.IsHexAddress(text) {
+		return reflect.Value{}, fmt.Errorf("invalid address %q", text)
+	}
+	return reflect.ValueOf(common.HexToAddress(text)), nil
+}
+
+func coerceBool(arg interface{}) (reflect.Value, error) {
+	switch value := arg.(type) {
+	case bool:
+		return reflect.ValueOf(value), nil
+	case string:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid boolean %q", value)
+		}
+		return reflect.ValueOf(parsed), nil
+	}
+	return reflect.Value{}, fmt.Errorf("expected a boolean, got %T", arg)
+}
+
+func coerceBytes(arg interface{}) ([]byte, error) {
+	switch value := arg.(type) {
+	case []byte:
+		return value, nil
+	case string:
+		data, err := hexutil.Decode(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex %q: %w", value, err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("expected hex bytes, got %T", arg)
+}
+
+func coerceFixedBytes(argType abi.Type, arg interface{}) (reflect.Value, error) {
+	data, err := coerceBytes(arg)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if len(data) > argType.Size {
+		return reflect.Value{}, fmt.Errorf("%d bytes do not fit in bytes%d", len(data), argType.Size)
+	}
+	value := reflect.New(argType.GetType()).Elem()
+	reflect.Copy(value, reflect.ValueOf(data))
+	return value, nil
+}
+
+func coerceInteger(argType abi.Type, arg interface{}) (reflect.Value, error) {
+	number, err := toBigInt(arg)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !fitsInteger(argType, number) {
+		return reflect.Value{}, fmt.Errorf("%s is out of range", number.String())
+	}
+	value := reflect.New(argType.GetType()).Elem()
+	switch value.Kind() {
+	case reflect.Ptr:
+		return reflect.ValueOf(number), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(number.Int64())
+	default:
+		value.SetUint(number.Uint64())
+	}
+	return value, nil
+}
+
+func toBigInt(arg interface{}) (*big.Int, error) {
+	switch value := arg.(type) {
+	case *big.Int:
+		if value == nil {
+			return nil, errors.New("nil integer")
+		}
+		return value, nil
+	case string:
+		return parseBigInt(strings.TrimSpace(value))
+	case json.Number:
+		return parseBigInt(value.String())
+	case float64:
+		if value != math.Trunc(value) || math.Abs(value) > 1<<53 {
+			return nil, fmt.Errorf("%v is not an exact integer, pass it as a string", value)
+		}
+		return big.NewInt(int64(value)), nil
+	case int, int8, int16, int32, int64:
+		return big.NewInt(reflect.ValueOf(value).Int()), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return new(big.Int).SetUint64(reflect.ValueOf(value).Uint()), nil
+	}
+	return nil, fmt.Errorf("expected an integer, got %T", arg)
+}
+
+func parseBigInt(text string) (*big.Int, error) {
+	number, ok := new(big.Int), false
+	negative := strings.HasPrefix(text, "-")
+	digits := strings.TrimPrefix(text, "-")
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		number, ok = number.SetString(digits[2:], 16)
+	} else {
+		number, ok = number.SetString(digits, 10)
+	}
+	if !ok || strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return nil, fmt.Errorf("invalid integer %q", text)
+	}
+	if negative {
+		number.Neg(number)
+	}
+	return number, nil
+}
+
+func fitsInteger(argType abi.Type, number *big.Int) bool {
+	if argType.T == abi.UintTy {
+		return number.Sign() >= 0 && number.BitLen() <= argType.Size
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(argType.Size-1))
+	return number.Cmp(new(big.Int).Neg(limit)) >= 0 && number.Cmp(limit) < 0
+}
+
+func coerceList(argType abi.Type, arg interface{}, path string) (reflect.Value, error) {
+	items, err := toList(arg)
+	if err != nil {
+		return reflect.Value{}, &ArgumentError{Path: path, Type: argType.String(), Err: err}
+	}
+	var list reflect.Value
+	if argType.T == abi.ArrayTy {
+		if len(items) != argType.Size {
+			err := fmt.Errorf("expected %d elements, got %d", argType.Size, len(items))
+			return reflect.Value{}, &ArgumentError{Path: path, Type: argType.String(), Err: err}
+		}
+		list = reflect.New(argType.GetType()).Elem()
+	} else {
+		list = reflect.MakeSlice(argType.GetType(), len(items), len(items))
+	}
+	for i, item := range items {
+		value, err := coerce(*argType.Elem, item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		list.Index(i).Set(value)
+	}
+	return list, nil
+}
+
+func coerceTuple(argType abi.Type, arg interface{}, path string) (reflect.Value, error) {
+	if text, ok := arg.(string); ok && strings.HasPrefix(strings.TrimSpace(text), "{") {
+		var object map[string]interface{}
+		if err := decodeJSON(text, &object); err != nil {
+			return reflect.Value{}, &ArgumentError{Path: path, Type: argType.String(), Err: err}
+		}
+		arg = object
+	}
+	items := make([]interface{}, len(argType.TupleElems))
+	if object, ok := arg.(map[string]interface{}); ok {
+		for i, name := range argType.TupleRawNames {
+			item, ok := object[name]
+			if !ok {
+				err := fmt.Errorf("missing component %q", name)
+				return reflect.Value{}, &ArgumentError{Path: path, Type: argType.String(), Err: err}
+			}
+			items[i] = item
+		}
+	} else {
+		list, err := toList(arg)
+		if err == nil && len(list) != len(items) {
+			err = fmt.Errorf("expected %d components, got %d", len(items), len(list))
+		}
+		if err != nil {
+			return reflect.Value{}, &ArgumentError{Path: path, Type: argType.String(), Err: err}
+		}
+		copy(items, list)
+	}
+	tuple := reflect.New(argType.TupleType).Elem()
+	for i, elem := range argType.TupleElems {
+		value, err := coerce(*elem, items[i], path+"."+argType.TupleRawNames[i])
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		tuple.Field(i).Set(value)
+	}
+	return tuple, nil
+}
+
+func toList(arg interface{}) ([]interface{}, error) {
+	if text, ok := arg.(string); ok {
+		var list []interface{}
+		if err := decodeJSON(text, &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	value := reflect.ValueOf(arg)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list, got %T", arg)
+	}
+	list := make([]interface{}, value.Len())
+	for i := range list {
+		list[i] = value.Index(i).Interface()
+	}
+	return list, nil
+}
+
+func decodeJSON(text string, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON %q: %w", text, err)
+	}
+	return nil
+}
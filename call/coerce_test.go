@@ -0,0 +1,77 @@
+package call
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMethod(t *testing.T, signature string) abi.Method {
+	method := parseNewMethod(signature)
+	contractAbi, err := repackAbi([]Method{method})
+	assert.NoError(t, err)
+	return contractAbi.Methods[method.Name]
+}
+
+func TestCoerceArgs_Scalars(t *testing.T) {
+	method := testMethod(t, "scalars(address,uint256,int24,uint8,bool,bytes32,bytes,string)(uint256)")
+	args, err := CoerceArgs(method, []interface{}{
+		"0xdAC17F958D2ee523a2206206994597C13D831ec7",
+		"0xff",
+		json.Number("-8388608"),
+		float64(18),
+		"true",
+		"0x01ff",
+		"0x",
+		"text",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"), args[0])
+	assert.Equal(t, big.NewInt(255), args[1])
+	assert.Equal(t, big.NewInt(-8388608), args[2])
+	assert.Equal(t, uint8(18), args[3])
+	assert.Equal(t, true, args[4])
+	assert.Equal(t, [32]byte{0x01, 0xff}, args[5])
+	assert.Equal(t, []byte{}, args[6])
+	assert.Equal(t, "text", args[7])
+
+	_, err = method.Inputs.Pack(args...)
+	assert.NoError(t, err)
+}
+
+func TestCoerceArgs_Nested(t *testing.T) {
+	method := testMethod(t, "nested(address[],uint256[2],(address,uint256))(uint256)")
+	args, err := ParseArgs(method, []string{
+		`["0xdAC17F958D2ee523a2206206994597C13D831ec7"]`,
+		`[1, "2"]`,
+		`{"input2component0": "0xdAC17F958D2ee523a2206206994597C13D831ec7", "input2component1": 3}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")}, args[0])
+	assert.Equal(t, [2]*big.Int{big.NewInt(1), big.NewInt(2)}, args[1])
+
+	_, err = method.Inputs.Pack(args...)
+	assert.NoError(t, err)
+}
+
+func TestCoerceArgs_Errors(t *testing.T) {
+	method := testMethod(t, "errors(uint8,uint256[])(uint256)")
+
+	_, err := CoerceArgs(method, []interface{}{"256", []interface{}{}})
+	assert.EqualError(t, err, `errors argument 0 (uint8): 256 is out of range`)
+
+	_, err = CoerceArgs(method, []interface{}{1, []interface{}{"1", "x"}})
+	assert.EqualError(t, err, `errors argument 1[1] (uint256): invalid integer "x"`)
+
+	_, err = CoerceArgs(method, []interface{}{1.5, []interface{}{}})
+	var argErr *ArgumentError
+	assert.ErrorAs(t, err, &argErr)
+	assert.Equal(t, 0, argErr.Index)
+
+	_, err = CoerceArgs(method, []interface{}{1})
+	assert.EqualError(t, err, "errors expects 2 arguments, got 1")
+}
@@ -1,59 +1,170 @@
 package call
 
+import "github.com/depocket/multicall-go/core"
+
 type Chain string
 
+type MultiCallVersion int
+
+const (
+	MultiCallUnknown MultiCallVersion = iota
+	MultiCallV1
+	MultiCallV2
+	MultiCallV3
+)
+
+// ChainConfig describes a chain. Url is the primary RPC endpoint and Urls lists
+// further endpoints; a zero MultiCallVersion means the version is unknown.
+// Limits, when set, apply to every endpoint of the chain.
 type ChainConfig struct {
-	MultiCallAddress string
-	Url              string
+	ChainId          uint64           `json:"chainId,omitempty" yaml:"chainId,omitempty"`
+	MultiCallAddress string           `json:"multicallAddress,omitempty" yaml:"multicallAddress,omitempty"`
+	MultiCallVersion MultiCallVersion `json:"multicallVersion,omitempty" yaml:"multicallVersion,omitempty"`
+	DeploymentBlock  uint64           `json:"deploymentBlock,omitempty" yaml:"deploymentBlock,omitempty"`
+	Url              string           `json:"url,omitempty" yaml:"url,omitempty"`
+	Urls             []string         `json:"urls,omitempty" yaml:"urls,omitempty"`
+	Limits           *core.Limits     `json:"limits,omitempty" yaml:"limits,omitempty"`
 }
 
 const (
-	Arbitrum  Chain = "arbitrum"
-	Aurora          = "aurora"
-	Avalanche       = "avalanche"
-	Bsc             = "bsc"
-	Ethereum        = "ethereum"
-	Fantom          = "fantom"
-	Moonbeam        = "moonbeam"
-	Moonriver       = "moonriver"
-	Celo            = "celo"
+	Arbitrum     Chain = "arbitrum"
+	Aurora       Chain = "aurora"
+	Avalanche    Chain = "avalanche"
+	Bsc          Chain = "bsc"
+	Ethereum     Chain = "ethereum"
+	Fantom       Chain = "fantom"
+	Moonbeam     Chain = "moonbeam"
+	Moonriver    Chain = "moonriver"
+	Celo         Chain = "celo"
+	Polygon      Chain = "polygon"
+	Optimism     Chain = "optimism"
+	Base         Chain = "base"
+	Gnosis       Chain = "gnosis"
+	PolygonZkEvm Chain = "polygon_zkevm"
+	ZkSyncEra    Chain = "zksync_era"
+	Linea        Chain = "linea"
+	Scroll       Chain = "scroll"
 )
 
+const multiCall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
 var DefaultChainConfigs = map[Chain]ChainConfig{
 	Arbitrum: {
+		ChainId:          42161,
 		MultiCallAddress: "0x7a7443f8c577d537f1d8cd4a629d40a3148dd7ee",
 		Url:              "https://arb1.arbitrum.io/rpc",
 	},
 	Aurora: {
+		ChainId:          1313161554,
 		MultiCallAddress: "0x88b373B83166E72FD55648Ce114712633f1782E2",
 		Url:              "https://mainnet.aurora.dev",
 	},
 	Avalanche: {
+		ChainId:          43114,
 		MultiCallAddress: "0xa00FB557AA68d2e98A830642DBbFA534E8512E5f",
 		Url:              "https://api.avax.network/ext/bc/C/rpc",
 	},
 	Bsc: {
+		ChainId:          56,
 		MultiCallAddress: "0xAD38F5025CBe01c8637BF683B3B68096A1c882CA",
 		Url:              "https://bsc-dataseed1.ninicoin.io",
+		Urls:             []string{"https://bsc-dataseed.binance.org"},
 	},
 	Ethereum: {
+		ChainId:          1,
 		MultiCallAddress: "0x5BA1e12693Dc8F9c48aAD8770482f4739bEeD696",
-		Url:              "https://mainnet.infura.io/v3/9aa3d95b3bc440fa88ea12eaa4456161",
+		MultiCallVersion: MultiCallV2,
+		DeploymentBlock:  12336033,
+		Url:              "https://eth.llamarpc.com",
+		Urls:             []string{"https://cloudflare-eth.com", "https://rpc.ankr.com/eth"},
 	},
 	Fantom: {
+		ChainId:          250,
 		MultiCallAddress: "0x7F4e475462A0fA0F1e2C69d50866D54505F99D72",
 		Url:              "https://rpcapi.fantom.network",
 	},
 	Moonbeam: {
+		ChainId:          1284,
 		MultiCallAddress: "0x6477204E12A7236b9619385ea453F370aD897bb2",
 		Url:              "https://moonbeam.public.blastapi.io",
 	},
 	Moonriver: {
+		ChainId:          1285,
 		MultiCallAddress: "0xEae947bF407A4a4f1c5a6A73312734A2863e3855",
 		Url:              "https://moonriver.public.blastapi.io",
 	},
 	Celo: {
+		ChainId:          42220,
 		MultiCallAddress: "0x7F4e475462A0fA0F1e2C69d50866D54505F99D72",
 		Url:              "https://forno.celo.org",
 	},
+	Polygon: {
+		ChainId:          137,
+		MultiCallAddress: multiCall3Address,
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  25770160,
+		Url:              "https://polygon-rpc.com",
+	},
+	Optimism: {
+		ChainId:          10,
+		MultiCallAddress: multiCall3Address,
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  4286263,
+		Url:              "https://mainnet.optimism.io",
+	},
+	Base: {
+		ChainId:          8453,
+		MultiCallAddress: multiCall3Address,
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  5022,
+		Url:              "https://mainnet.base.org",
+	},
+	Gnosis: {
+		ChainId:          100,
+		MultiCallAddress: multiCall3Address,
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  21022491,
+		Url:              "https://rpc.gnosischain.com",
+	},
+	PolygonZkEvm: {
+		ChainId:          1101,
+		MultiCallAddress: multiCall3Address,
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  57746,
+		Url:              "https://zkevm-rpc.com",
+	},
+	ZkSyncEra: {
+		ChainId:          324,
+		MultiCallAddress: "0xF9cda624FBC7e059355ce98a31693d299FACd963",
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  3908235,
+		Url:              "https://mainnet.era.zksync.io",
+	},
+	Linea: {
+		ChainId:          59144,
+		MultiCallAddress: multiCall3Address,
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  42,
+		Url:              "https://rpc.linea.build",
+	},
+	Scroll: {
+		ChainId:          534352,
+		MultiCallAddress: multiCall3Address,
+		MultiCallVersion: MultiCallV3,
+		DeploymentBlock:  14,
+		Url:              "https://rpc.scroll.io",
+	},
+}
+
+// Endpoints returns Url followed by Urls without duplicates.
+func (config ChainConfig) Endpoints() []string {
+	endpoints := make([]string, 0, len(config.Urls)+1)
+	seen := make(map[string]bool, len(config.Urls)+1)
+	for _, url := range append([]string{config.Url}, config.Urls...) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			endpoints = append(endpoints, url)
+		}
+	}
+	return endpoints
 }
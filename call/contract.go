@@ -3,15 +3,20 @@ package call
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/depocket/multicall-go/core"
 	"github.com/depocket/multicall-go/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type Component struct {
@@ -36,53 +41,178 @@ type Method struct {
 }
 
 type Result struct {
-	Success    bool          `json:"success"`
-	ReturnData []interface{} `json:"return_data"`
+	Success      bool          `json:"success"`
+	ReturnData   []interface{} `json:"return_data"`
+	RevertReason string        `json:"revert_reason,omitempty"`
 }
 
 type ContractBuilder interface {
 	WithClient(ethClient *ethclient.Client) ContractBuilder
+	WithRPCClient(rpcClient *rpc.Client) ContractBuilder
+	WithPool(pool *ClientPool) ContractBuilder
+	WithRetryPolicy(policy core.RetryPolicy) ContractBuilder
+	WithLimiters(limiters *core.Limiters) ContractBuilder
+	WithHealthTracker(health *core.HealthTracker) ContractBuilder
+	WithCache(cache core.Cache, latestTTL time.Duration) ContractBuilder
 	AtAddress(contractAddress string) ContractBuilder
 	AddMethod(signature string) *Contract
 	Abi() abi.ABI
 	Build() *Contract
 	WithChainConfig(config ChainConfig) *Contract
+	WithChain(chain Chain) *Contract
+	WithVerifiedChainConfig(ctx context.Context, config ChainConfig) (*Contract, error)
 }
 
+// Contract either uses the client set with WithClient, or the pooled clients
+// of its chain endpoints, which are dialed on the first execution. Calls fail
+// over between the chain endpoints following the retry policy, and count
+// against the endpoint limits shared by every Contract using the same limiters.
+// Endpoints whose circuit the health tracker opened are skipped. With a cache,
+// sub-call results are shared by every Contract of the same chain.
 type Contract struct {
-	ethClient   *ethclient.Client
-	contractAbi abi.ABI
-	rawMethods  map[string]string
-	methods     []Method
-	calls       []core.Call
-	multiCaller *core.MultiCaller
+	ethClient        *ethclient.Client
+	rpcClient        *rpc.Client
+	pool             *ClientPool
+	urls             []string
+	retryPolicy      core.RetryPolicy
+	limiters         *core.Limiters
+	health           *core.HealthTracker
+	cache            core.Cache
+	latestTTL        time.Duration
+	chainId          uint64
+	multiCallAddress common.Address
+	multiCallVersion MultiCallVersion
+	contractAbi      abi.ABI
+	rawMethods       map[string]string
+	methods          []Method
+	calls            []core.Call
+	callerMu         sync.Mutex
+	multiCaller      *core.MultiCaller
 }
 
 func NewContractBuilder() ContractBuilder {
 	contract := &Contract{
-		calls:      make([]core.Call, 0),
-		methods:    make([]Method, 0),
-		rawMethods: make(map[string]string, 0),
+		pool:        DefaultPool,
+		retryPolicy: core.DefaultRetryPolicy,
+		limiters:    core.DefaultLimiters,
+		health:      core.DefaultHealth,
+		calls:       make([]core.Call, 0),
+		methods:     make([]Method, 0),
+		rawMethods:  make(map[string]string, 0),
 	}
 
-	return contract.WithChainConfig(DefaultChainConfigs[Ethereum])
+	return contract.WithChain(Ethereum)
+}
+
+func (ct *Contract) WithChain(chain Chain) *Contract {
+	config, ok := DefaultRegistry.Get(chain)
+	if !ok {
+		panic("Chain " + string(chain) + " is not registered")
+	}
+	return ct.WithChainConfig(config)
 }
 
 func (ct *Contract) WithChainConfig(config ChainConfig) *Contract {
-	if config.MultiCallAddress == "" || config.Url == "" {
+	endpoints := config.Endpoints()
+	if config.MultiCallAddress == "" || len(endpoints) == 0 {
 		panic("Invalid configuration. MultiCallAddress and Url must be set")
 	}
 
-	client, err := ethclient.Dial(config.Url)
-	if err != nil {
-		panic(err)
+	ct.ethClient = nil
+	ct.rpcClient = nil
+	ct.urls = endpoints
+	ct.chainId = config.ChainId
+	if config.Limits != nil {
+		for _, url := range endpoints {
+			ct.limiters.SetEndpointLimits(url, *config.Limits)
+		}
 	}
+	ct.AtAddress(config.MultiCallAddress)
+	ct.multiCallVersion = config.MultiCallVersion
+	return ct
+}
 
-	return ct.WithClient(client).AtAddress(config.MultiCallAddress).Build()
+// WithVerifiedChainConfig is WithChainConfig that first verifies the endpoint
+// serves the configured chain and has a multicall contract deployed.
+func (ct *Contract) WithVerifiedChainConfig(ctx context.Context, config ChainConfig) (*Contract, error) {
+	ct.WithChainConfig(config)
+	for _, url := range ct.urls {
+		client, err := ct.pool.Client(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		verification, err := Verify(ctx, client, url, config)
+		if err != nil {
+			return nil, err
+		}
+		if ct.multiCallVersion == MultiCallUnknown {
+			ct.multiCallVersion = verification.MultiCallVersion
+		}
+	}
+	return ct, nil
 }
 
+// WithClient executes through ethClient, which reads at the latest and pending
+// blocks and at block numbers only. WithRPCClient supports every core.BlockRef.
 func (ct *Contract) WithClient(ethClient *ethclient.Client) ContractBuilder {
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
 	ct.ethClient = ethClient
+	ct.rpcClient = nil
+	ct.urls = nil
+	ct.chainId = 0
+	ct.multiCaller = nil
+	return ct
+}
+
+func (ct *Contract) WithRPCClient(rpcClient *rpc.Client) ContractBuilder {
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
+	ct.ethClient = ethclient.NewClient(rpcClient)
+	ct.rpcClient = rpcClient
+	ct.urls = nil
+	ct.chainId = 0
+	ct.multiCaller = nil
+	return ct
+}
+
+func (ct *Contract) WithPool(pool *ClientPool) ContractBuilder {
+	ct.pool = pool
+	return ct
+}
+
+func (ct *Contract) WithRetryPolicy(policy core.RetryPolicy) ContractBuilder {
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
+	ct.retryPolicy = policy
+	ct.multiCaller = nil
+	return ct
+}
+
+func (ct *Contract) WithLimiters(limiters *core.Limiters) ContractBuilder {
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
+	ct.limiters = limiters
+	ct.multiCaller = nil
+	return ct
+}
+
+func (ct *Contract) WithHealthTracker(health *core.HealthTracker) ContractBuilder {
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
+	ct.health = health
+	ct.multiCaller = nil
+	return ct
+}
+
+// WithCache looks sub-calls up in cache before executing them. Reads at a block
+// number are cached for good, reads of the latest block for latestTTL.
+func (ct *Contract) WithCache(cache core.Cache, latestTTL time.Duration) ContractBuilder {
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
+	ct.cache = cache
+	ct.latestTTL = latestTTL
+	ct.multiCaller = nil
 	return ct
 }
 
@@ -91,12 +221,84 @@ func (ct *Contract) Build() *Contract {
 }
 
 func (ct *Contract) AtAddress(address string) ContractBuilder {
-	caller, err := core.NewMultiCaller(ct.ethClient, common.HexToAddress(address))
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
+	ct.multiCallAddress = common.HexToAddress(address)
+	ct.multiCallVersion = MultiCallUnknown
+	ct.multiCaller = nil
+	return ct
+}
+
+func (ct *Contract) caller(ctx context.Context) (*core.MultiCaller, error) {
+	ct.callerMu.Lock()
+	defer ct.callerMu.Unlock()
+	if ct.multiCaller != nil {
+		return ct.multiCaller, nil
+	}
+	endpoints, err := ct.endpoints(ctx)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	client := endpoints[0].Client
+	if ct.ethClient == nil {
+		client = core.NewFailoverClient(endpoints, ct.retryPolicy).WithHealth(ct.health)
+	}
+	caller, err := core.NewMultiCaller(client, ct.multiCallAddress)
+	if err != nil {
+		return nil, err
 	}
+	caller.Cache = ct.cache
+	caller.CacheNamespace = ct.cacheNamespace()
+	caller.LatestTTL = ct.latestTTL
+	caller.Flights = core.DefaultFlights
+	caller.AggregateOnly = ct.multiCallVersion == MultiCallV1
 	ct.multiCaller = caller
-	return ct
+	return caller, nil
+}
+
+// cacheNamespace is the chain id, or for chains without one the primary
+// endpoint. Contracts built WithClient only share entries with themselves.
+func (ct *Contract) cacheNamespace() string {
+	switch {
+	case ct.chainId != 0:
+		return strconv.FormatUint(ct.chainId, 10)
+	case len(ct.urls) > 0:
+		return ct.urls[0]
+	default:
+		return fmt.Sprintf("%p", ct.ethClient)
+	}
+}
+
+// endpoints returns the limited pooled clients of the chain endpoints, or the
+// client set with WithClient or WithRPCClient.
+func (ct *Contract) endpoints(ctx context.Context) ([]core.Endpoint, error) {
+	if ct.rpcClient != nil {
+		return []core.Endpoint{{Client: core.NewRPCClient(ct.rpcClient)}}, nil
+	}
+	if ct.ethClient != nil {
+		return []core.Endpoint{{Client: ct.ethClient}}, nil
+	}
+	if len(ct.urls) == 0 {
+		return nil, errors.New("contract has neither a client nor a chain")
+	}
+	endpoints := make([]core.Endpoint, 0, len(ct.urls))
+	for _, url := range ct.urls {
+		endpointClient, err := ct.pool.RPCClient(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, core.Endpoint{Url: url, Client: ct.limiters.Client(url, endpointClient)})
+	}
+	return endpoints, nil
+}
+
+// CodeAt reads the latest code at address through the client the calls use.
+func (ct *Contract) CodeAt(ctx context.Context, address string) ([]byte, error) {
+	multiCaller, err := ct.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return multiCaller.Client.CodeAt(ctx, common.HexToAddress(address), nil)
 }
 
 func (ct *Contract) AddCall(callName string, contractAddress string, method string, args ...interface{}) *Contract {
@@ -113,6 +315,30 @@ func (ct *Contract) AddCall(callName string, contractAddress string, method stri
 	return ct
 }
 
+// AddCoercedCall is AddCall for untyped arguments: they are converted with
+// CoerceArgs first and an error is returned instead of panicking.
+func (ct *Contract) AddCoercedCall(callName string, contractAddress string, method string, args ...interface{}) (*Contract, error) {
+	abiMethod, ok := ct.contractAbi.Methods[method]
+	if !ok {
+		return ct, fmt.Errorf("method %s is not declared", method)
+	}
+	values, err := CoerceArgs(abiMethod, args)
+	if err != nil {
+		return ct, err
+	}
+	callData, err := ct.contractAbi.Pack(method, values...)
+	if err != nil {
+		return ct, err
+	}
+	ct.calls = append(ct.calls, core.Call{
+		Method:   method,
+		Target:   common.HexToAddress(contractAddress),
+		Key:      callName,
+		CallData: callData,
+	})
+	return ct, nil
+}
+
 func (ct *Contract) AddMethod(signature string) *Contract {
 	existCall, ok := ct.rawMethods[strings.ToLower(signature)]
 	if ok {
@@ -131,13 +357,72 @@ func (ct *Contract) AddMethod(signature string) *Contract {
 	return ct
 }
 
+func (ct *Contract) HasMethod(name string) bool {
+	_, ok := ct.contractAbi.Methods[name]
+	return ok
+}
+
+// ResolveMethod adds signature unless a method with the same name, inputs and
+// outputs is already on the ABI, and returns the name to pass to AddCall.
+// Overloads, including a method differing only in its outputs, are named as
+// go-ethereum names them, e.g. balanceOf0 for the second balanceOf.
+func (ct *Contract) ResolveMethod(signature string) string {
+	parsed, err := repackAbi([]Method{parseNewMethod(signature)})
+	if err != nil {
+		panic(err)
+	}
+	var want abi.Method
+	for _, method := range parsed.Methods {
+		want = method
+	}
+	if name, ok := ct.methodLike(want); ok {
+		return name
+	}
+	ct.AddMethod(signature)
+	name, _ := ct.methodLike(want)
+	return name
+}
+
+// methodLike returns the name of the method with the signature and output
+// types of want.
+func (ct *Contract) methodLike(want abi.Method) (string, bool) {
+	for name, method := range ct.contractAbi.Methods {
+		if method.Sig == want.Sig && sameTypes(method.Outputs, want.Outputs) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func sameTypes(a, b abi.Arguments) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type.String() != b[i].Type.String() {
+			return false
+		}
+	}
+	return true
+}
+
 func (ct *Contract) Abi() abi.ABI {
 	return ct.contractAbi
 }
 
 func (ct *Contract) Call(blockNumber *big.Int) (*big.Int, map[string][]interface{}, error) {
+	return ct.CallAtBlock(context.Background(), core.BlockNumber(blockNumber))
+}
+
+// CallAtBlock is Call at any block tag, number or hash.
+func (ct *Contract) CallAtBlock(ctx context.Context, block core.BlockRef) (*big.Int, map[string][]interface{}, error) {
 	res := make(map[string][]interface{})
-	blockNumber, results, err := ct.multiCaller.StrictlyExecute(ct.calls, blockNumber)
+	multiCaller, err := ct.caller(ctx)
+	if err != nil {
+		ct.ClearCall()
+		return nil, nil, err
+	}
+	blockNumber, results, err := multiCaller.StrictlyExecuteAt(ctx, ct.calls, block)
 	if err != nil {
 		ct.ClearCall()
 		return nil, nil, err
@@ -154,18 +439,84 @@ func (ct *Contract) Call(blockNumber *big.Int) (*big.Int, map[string][]interface
 }
 
 func (ct *Contract) FlexibleCall(ctx context.Context, requireSuccess bool) (map[string]Result, error) {
-	res := make(map[string]Result)
-	results, err := ct.multiCaller.Execute(ctx, ct.calls, requireSuccess)
+	return ct.FlexibleCallAt(ctx, requireSuccess, nil)
+}
+
+func (ct *Contract) FlexibleCallAt(ctx context.Context, requireSuccess bool, blockNumber *big.Int) (map[string]Result, error) {
+	return ct.FlexibleCallAtBlock(ctx, requireSuccess, core.BlockNumber(blockNumber))
+}
+
+// FlexibleCallAtBlock is FlexibleCall at any block tag, number or hash.
+func (ct *Contract) FlexibleCallAtBlock(ctx context.Context, requireSuccess bool, block core.BlockRef) (map[string]Result, error) {
+	multiCaller, err := ct.caller(ctx)
 	if err != nil {
 		ct.ClearCall()
 		return nil, err
 	}
+	results, err := multiCaller.ExecuteAtBlock(ctx, ct.calls, requireSuccess, block)
+	if err != nil {
+		ct.ClearCall()
+		return nil, err
+	}
+	res, err := ct.decode(results)
+	ct.ClearCall()
+	return res, err
+}
+
+// FlexibleCallWithBlock is FlexibleCallAtBlock that also reports the number
+// and hash of the block the calls executed at, so stored results can later be
+// checked with a Verifier. Reading the hash needs block headers, which
+// contracts built WithClient cannot read, so they fail with
+// core.ErrBlockHeaderUnsupported.
+func (ct *Contract) FlexibleCallWithBlock(ctx context.Context, requireSuccess bool, block core.BlockRef) (core.Block, map[string]Result, error) {
+	defer ct.ClearCall()
+	if !ct.readsHeaders() {
+		return core.Block{}, nil, core.ErrBlockHeaderUnsupported
+	}
+	multiCaller, err := ct.caller(ctx)
+	if err != nil {
+		return core.Block{}, nil, err
+	}
+	executedAt, results, err := multiCaller.ExecuteBlock(ctx, ct.calls, requireSuccess, block)
+	if err != nil {
+		return core.Block{}, nil, err
+	}
+	res, err := ct.decode(results)
+	if err != nil {
+		return core.Block{}, nil, err
+	}
+	return executedAt, res, nil
+}
+
+// readsHeaders reports whether the clients of the contract can read block
+// headers, which every client can but one set WithClient.
+func (ct *Contract) readsHeaders() bool {
+	return ct.ethClient == nil || ct.rpcClient != nil
+}
+
+// MultiCaller returns the caller executing the calls of the contract, so calls
+// batched elsewhere go through the same endpoints and multicall entry points.
+func (ct *Contract) MultiCaller(ctx context.Context) (*core.MultiCaller, error) {
+	return ct.caller(ctx)
+}
+
+// Verifier checks blocks reported by FlexibleCallWithBlock against the
+// canonical chain through the endpoints of the contract.
+func (ct *Contract) Verifier(ctx context.Context) (*core.BlockVerifier, error) {
+	multiCaller, err := ct.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewBlockVerifier(multiCaller.Client), nil
+}
+
+func (ct *Contract) decode(results map[string]core.CallResponse) (map[string]Result, error) {
+	res := make(map[string]Result)
 	for _, call := range ct.calls {
 		callSuccess := results[call.Key].Status
 		if callSuccess {
 			data, err := ct.contractAbi.Unpack(call.Method, results[call.Key].ReturnData)
 			if err != nil {
-				ct.ClearCall()
 				return nil, err
 			}
 			res[call.Key] = Result{
@@ -174,19 +525,82 @@ func (ct *Contract) FlexibleCall(ctx context.Context, requireSuccess bool) (map[
 			}
 		} else {
 			res[call.Key] = Result{
-				Success:    results[call.Key].Status,
-				ReturnData: nil,
+				Success:      results[call.Key].Status,
+				ReturnData:   nil,
+				RevertReason: utils.RevertReason(results[call.Key].ReturnData),
 			}
 		}
 	}
+	return res, nil
+}
+
+// HedgedCall sends the pending calls to every chain endpoint in turn, one more
+// each delay without a success, and decodes the first successful answer.
+func (ct *Contract) HedgedCall(ctx context.Context, requireSuccess bool, block core.BlockRef, delay time.Duration) (map[string]Result, *core.QuorumResult, error) {
+	return ct.quorumCall(ctx, func(caller *core.QuorumCaller) (*core.QuorumResult, error) {
+		return caller.Hedged(ctx, ct.calls, requireSuccess, block, delay)
+	})
+}
+
+// QuorumCall sends the pending calls to every chain endpoint at the same block
+// and decodes the answer that quorum endpoints agree on. Endpoints that answered
+// differently are listed in the returned QuorumResult.
+func (ct *Contract) QuorumCall(ctx context.Context, requireSuccess bool, block core.BlockRef, quorum int) (map[string]Result, *core.QuorumResult, error) {
+	return ct.quorumCall(ctx, func(caller *core.QuorumCaller) (*core.QuorumResult, error) {
+		return caller.Quorum(ctx, ct.calls, requireSuccess, block, quorum)
+	})
+}
+
+func (ct *Contract) quorumCall(ctx context.Context, execute func(caller *core.QuorumCaller) (*core.QuorumResult, error)) (map[string]Result, *core.QuorumResult, error) {
+	defer ct.ClearCall()
+	endpoints, err := ct.endpoints(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	caller, err := core.NewQuorumCaller(endpoints, ct.multiCallAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	quorumResult, err := execute(caller)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := ct.decode(quorumResult.Results)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, quorumResult, nil
+}
+
+// RawCall executes the pending calls like FlexibleCall but returns the undecoded
+// responses, so callers can inspect return data that does not match the method outputs.
+func (ct *Contract) RawCall(ctx context.Context, requireSuccess bool) (map[string]core.CallResponse, error) {
+	return ct.RawCallAtBlock(ctx, requireSuccess, core.LatestBlock)
+}
+
+func (ct *Contract) RawCallAtBlock(ctx context.Context, requireSuccess bool, block core.BlockRef) (map[string]core.CallResponse, error) {
+	multiCaller, err := ct.caller(ctx)
+	if err != nil {
+		ct.ClearCall()
+		return nil, err
+	}
+	results, err := multiCaller.ExecuteAtBlock(ctx, ct.calls, requireSuccess, block)
 	ct.ClearCall()
-	return res, err
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
 func (ct *Contract) ClearCall() {
 	ct.calls = []core.Call{}
 }
 
+func MethodName(signature string) string {
+	methodPath := strings.SplitN(utils.CleanSpaces(signature), "(", 2)[0]
+	return strings.Replace(methodPath, "function", "", 1)
+}
+
 func parseNewMethod(signature string) Method {
 	signature = utils.CleanSpaces(signature)
 	methodPaths := strings.Split(signature, "(")
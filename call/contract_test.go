@@ -107,3 +107,17 @@ func TestContractBuilder_AddMethodTuple(t *testing.T) {
 		"function claimableRewardsThree(address input0) view returns((address,uint256)[] output0, (address,address)[] output1, uint256 output2, (address,address,address,uint256)[] output3)",
 	)
 }
+
+func TestContract_ResolveMethod(t *testing.T) {
+	contract := NewContractBuilder().AddMethod("decimals()(uint256)")
+	assert.Equal(t, "decimals", contract.ResolveMethod("decimals()(uint256)"))
+
+	// the same inputs with other outputs are a distinct overload
+	uint8Decimals := contract.ResolveMethod("decimals()(uint8)")
+	assert.Equal(t, "decimals0", uint8Decimals)
+	assert.Equal(t, "uint8", contract.Abi().Methods[uint8Decimals].Outputs[0].Type.String())
+	assert.Equal(t, uint8Decimals, contract.ResolveMethod("decimals()(uint8)"))
+
+	assert.Equal(t, "balanceOf", contract.ResolveMethod("balanceOf(address)(uint256)"))
+	assert.Equal(t, "balanceOf0", contract.ResolveMethod("balanceOf(address,uint256)(uint256)"))
+}
@@ -0,0 +1,122 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/depocket/multicall-go/core"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrPoolClosed = errors.New("client pool is closed")
+
+// ClientPool shares one connection per RPC URL. Connections are dialed on
+// first use and stay open until Close.
+type ClientPool struct {
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+	closed  bool
+}
+
+// pooledClient is ready once its dial finished, with err set when it failed.
+type pooledClient struct {
+	ready chan struct{}
+	err   error
+	rpc   *rpc.Client
+	eth   *ethclient.Client
+}
+
+var DefaultPool = NewClientPool()
+
+func NewClientPool() *ClientPool {
+	return &ClientPool{clients: make(map[string]*pooledClient)}
+}
+
+func (p *ClientPool) Client(ctx context.Context, url string) (*ethclient.Client, error) {
+	client, err := p.dial(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return client.eth, nil
+}
+
+// RPCClient returns a client of url that can read at any core.BlockRef.
+func (p *ClientPool) RPCClient(ctx context.Context, url string) (*core.RPCClient, error) {
+	client, err := p.dial(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewRPCClient(client.rpc), nil
+}
+
+// dial returns the client of url, dialing it without holding the pool lock so
+// a slow endpoint does not hold up the others. Concurrent callers wait for the
+// same dial, and dial again when it failed.
+func (p *ClientPool) dial(ctx context.Context, url string) (*pooledClient, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		client, ok := p.clients[url]
+		if !ok {
+			client = &pooledClient{ready: make(chan struct{})}
+			p.clients[url] = client
+		}
+		p.mu.Unlock()
+
+		if !ok {
+			return client, p.connect(ctx, url, client)
+		}
+		select {
+		case <-client.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if client.err == nil {
+			return client, nil
+		}
+	}
+}
+
+func (p *ClientPool) connect(ctx context.Context, url string, client *pooledClient) error {
+	defer close(client.ready)
+	rpcClient, err := rpc.DialContext(ctx, url)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case err != nil:
+		client.err = err
+	case p.closed:
+		rpcClient.Close()
+		client.err = ErrPoolClosed
+	default:
+		client.rpc, client.eth = rpcClient, ethclient.NewClient(rpcClient)
+		return nil
+	}
+	if p.clients[url] == client {
+		delete(p.clients, url)
+	}
+	return client.err
+}
+
+// Close closes every connection. It is terminal: contracts keep the clients
+// they resolved, which stop working, and the pool dials no new connections.
+func (p *ClientPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for url, client := range p.clients {
+		select {
+		case <-client.ready:
+			if client.err == nil {
+				client.rpc.Close()
+			}
+		default:
+		}
+		delete(p.clients, url)
+	}
+}
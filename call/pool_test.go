@@ -0,0 +1,60 @@
+package call
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientPool_Lazy(t *testing.T) {
+	node := newVerifyNode(t, "0x6080", MultiCallV2)
+	defer node.Close()
+	pool := NewClientPool()
+	defer pool.Close()
+	config := ChainConfig{MultiCallAddress: "0x01", Url: node.URL}
+
+	first := NewContractBuilder().WithPool(pool).WithChainConfig(config)
+	second := NewContractBuilder().WithPool(pool).WithChainConfig(config)
+	assert.Empty(t, pool.clients)
+
+	_, err := first.caller(context.Background())
+	assert.NoError(t, err)
+	_, err = second.caller(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, pool.clients, 1)
+
+	pool.Close()
+	assert.Empty(t, pool.clients)
+	_, err = pool.Client(context.Background(), node.URL)
+	assert.ErrorIs(t, err, ErrPoolClosed)
+}
+
+func TestClientPool_Concurrent(t *testing.T) {
+	node := newVerifyNode(t, "0x6080", MultiCallV2)
+	defer node.Close()
+	pool := NewClientPool()
+	defer pool.Close()
+
+	clients := make([]*ethclient.Client, 10)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := pool.Client(context.Background(), node.URL)
+			assert.NoError(t, err)
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+	for _, client := range clients {
+		assert.Same(t, clients[0], client)
+	}
+
+	_, err := pool.Client(context.Background(), "unsupported://endpoint")
+	assert.Error(t, err)
+	assert.Len(t, pool.clients, 1)
+}
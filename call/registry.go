@@ -0,0 +1,229 @@
+package call
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/depocket/multicall-go/core"
+
+	"gopkg.in/yaml.v3"
+)
+
+const EnvPrefix = "MULTICALL_"
+
+var envSuffixes = []string{
+	"_MULTICALL_ADDRESS",
+	"_MULTICALL_VERSION",
+	"_DEPLOYMENT_BLOCK",
+	"_CHAIN_ID",
+	"_RATE_LIMIT",
+	"_MAX_CONCURRENT",
+	"_URLS",
+	"_URL",
+}
+
+// Registry is a concurrency safe set of chain configs.
+type Registry struct {
+	mu     sync.RWMutex
+	chains map[Chain]ChainConfig
+}
+
+var DefaultRegistry = NewDefaultRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{chains: make(map[Chain]ChainConfig)}
+}
+
+// NewDefaultRegistry returns a registry holding DefaultChainConfigs.
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for chain, config := range DefaultChainConfigs {
+		registry.chains[chain] = copyConfig(config)
+	}
+	return registry
+}
+
+// Register adds a chain or replaces its config.
+func (r *Registry) Register(chain Chain, config ChainConfig) error {
+	if chain == "" {
+		return fmt.Errorf("chain name must be set")
+	}
+	if config.MultiCallAddress == "" || len(config.Endpoints()) == 0 {
+		return fmt.Errorf("chain %s: MultiCallAddress and at least one url must be set", chain)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chains[chain] = copyConfig(config)
+	return nil
+}
+
+func (r *Registry) Get(chain Chain) (ChainConfig, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	config, ok := r.chains[chain]
+	return copyConfig(config), ok
+}
+
+func (r *Registry) ByChainId(chainId uint64) (Chain, ChainConfig, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for chain, config := range r.chains {
+		if config.ChainId == chainId {
+			return chain, copyConfig(config), true
+		}
+	}
+	return "", ChainConfig{}, false
+}
+
+func (r *Registry) Chains() []Chain {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	chains := make([]Chain, 0, len(r.chains))
+	for chain := range r.chains {
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+	return chains
+}
+
+// Merge overlays configs on the registry. Fields set in an override replace
+// the registered ones, a new chain must be complete.
+func (r *Registry) Merge(configs map[Chain]ChainConfig) error {
+	for chain, override := range configs {
+		config, _ := r.Get(chain)
+		if override.ChainId != 0 {
+			config.ChainId = override.ChainId
+		}
+		if override.MultiCallAddress != "" {
+			config.MultiCallAddress = override.MultiCallAddress
+		}
+		if override.MultiCallVersion != MultiCallUnknown {
+			config.MultiCallVersion = override.MultiCallVersion
+		}
+		if override.DeploymentBlock != 0 {
+			config.DeploymentBlock = override.DeploymentBlock
+		}
+		if override.Url != "" {
+			config.Url = override.Url
+		}
+		if len(override.Urls) > 0 {
+			config.Urls = override.Urls
+		}
+		if override.Limits != nil {
+			config.Limits = override.Limits
+		}
+		if err := r.Register(chain, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile merges a JSON or YAML file mapping chain names to configs.
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	configs := make(map[Chain]ChainConfig)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&configs)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&configs)
+	default:
+		return fmt.Errorf("unsupported chain file extension %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return r.Merge(configs)
+}
+
+// LoadEnv merges chains configured through environment variables. A file is
+// loaded from MULTICALL_CHAINS_FILE first, then variables named
+// MULTICALL_<CHAIN>_<FIELD> are applied, where FIELD is one of URL, URLS
+// (comma separated), MULTICALL_ADDRESS, MULTICALL_VERSION, CHAIN_ID and
+// DEPLOYMENT_BLOCK, e.g. MULTICALL_POLYGON_ZKEVM_URL.
+func (r *Registry) LoadEnv() error {
+	if path := os.Getenv(EnvPrefix + "CHAINS_FILE"); path != "" {
+		if err := r.LoadFile(path); err != nil {
+			return err
+		}
+	}
+	configs := make(map[Chain]ChainConfig)
+	for _, env := range os.Environ() {
+		parts := strings.SplitN(env, "=", 2)
+		if !strings.HasPrefix(parts[0], EnvPrefix) || parts[0] == EnvPrefix+"CHAINS_FILE" {
+			continue
+		}
+		name := strings.TrimPrefix(parts[0], EnvPrefix)
+		for _, suffix := range envSuffixes {
+			if !strings.HasSuffix(name, suffix) || len(name) == len(suffix) {
+				continue
+			}
+			chain := Chain(strings.ToLower(strings.TrimSuffix(name, suffix)))
+			config := configs[chain]
+			if err := setEnvField(&config, suffix, parts[1]); err != nil {
+				return fmt.Errorf("%s: %w", parts[0], err)
+			}
+			configs[chain] = config
+			break
+		}
+	}
+	return r.Merge(configs)
+}
+
+func setEnvField(config *ChainConfig, suffix string, value string) error {
+	var err error
+	switch suffix {
+	case "_URL":
+		config.Url = value
+	case "_URLS":
+		config.Urls = strings.Split(value, ",")
+	case "_MULTICALL_ADDRESS":
+		config.MultiCallAddress = value
+	case "_MULTICALL_VERSION":
+		var version int
+		version, err = strconv.Atoi(value)
+		config.MultiCallVersion = MultiCallVersion(version)
+	case "_CHAIN_ID":
+		config.ChainId, err = strconv.ParseUint(value, 10, 64)
+	case "_DEPLOYMENT_BLOCK":
+		config.DeploymentBlock, err = strconv.ParseUint(value, 10, 64)
+	case "_RATE_LIMIT":
+		config.Limits = withLimits(config.Limits)
+		config.Limits.RequestsPerSecond, err = strconv.ParseFloat(value, 64)
+	case "_MAX_CONCURRENT":
+		config.Limits = withLimits(config.Limits)
+		config.Limits.MaxConcurrent, err = strconv.Atoi(value)
+	}
+	return err
+}
+
+func withLimits(limits *core.Limits) *core.Limits {
+	if limits == nil {
+		return &core.Limits{}
+	}
+	return limits
+}
+
+func copyConfig(config ChainConfig) ChainConfig {
+	config.Urls = append([]string(nil), config.Urls...)
+	if config.Limits != nil {
+		limits := *config.Limits
+		config.Limits = &limits
+	}
+	return config
+}
@@ -0,0 +1,73 @@
+package call
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/depocket/multicall-go/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Merge(t *testing.T) {
+	registry := NewDefaultRegistry()
+	err := registry.Merge(map[Chain]ChainConfig{
+		Ethereum: {Url: "https://eth.example.org"},
+		"devnet": {ChainId: 1337, MultiCallAddress: "0x01", Url: "http://localhost:8545"},
+	})
+	assert.NoError(t, err)
+
+	ethereum, ok := registry.Get(Ethereum)
+	assert.True(t, ok)
+	assert.Equal(t, "https://eth.example.org", ethereum.Url)
+	assert.Equal(t, DefaultChainConfigs[Ethereum].MultiCallAddress, ethereum.MultiCallAddress)
+	assert.Equal(t, uint64(1), ethereum.ChainId)
+
+	chain, devnet, ok := registry.ByChainId(1337)
+	assert.True(t, ok)
+	assert.Equal(t, Chain("devnet"), chain)
+	assert.Equal(t, []string{"http://localhost:8545"}, devnet.Endpoints())
+
+	assert.Error(t, registry.Merge(map[Chain]ChainConfig{"incomplete": {ChainId: 5}}))
+	assert.Equal(t, "https://eth.llamarpc.com", DefaultChainConfigs[Ethereum].Url)
+}
+
+func TestRegistry_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chains.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+polygon:
+  urls: ["https://polygon.example.org"]
+devnet:
+  chainId: 1337
+  multicallAddress: "0x01"
+  multicallVersion: 3
+  url: http://localhost:8545
+`), 0o600))
+
+	registry := NewDefaultRegistry()
+	assert.NoError(t, registry.LoadFile(path))
+	polygon, _ := registry.Get(Polygon)
+	assert.Equal(t, []string{"https://polygon-rpc.com", "https://polygon.example.org"}, polygon.Endpoints())
+	devnet, ok := registry.Get("devnet")
+	assert.True(t, ok)
+	assert.Equal(t, MultiCallV3, devnet.MultiCallVersion)
+}
+
+func TestRegistry_LoadEnv(t *testing.T) {
+	t.Setenv("MULTICALL_POLYGON_ZKEVM_URL", "https://zkevm.example.org")
+	t.Setenv("MULTICALL_DEVNET_URLS", "http://localhost:8545,http://localhost:8546")
+	t.Setenv("MULTICALL_DEVNET_MULTICALL_ADDRESS", "0x01")
+	t.Setenv("MULTICALL_DEVNET_CHAIN_ID", "1337")
+	t.Setenv("MULTICALL_DEVNET_RATE_LIMIT", "12.5")
+	t.Setenv("MULTICALL_DEVNET_MAX_CONCURRENT", "4")
+
+	registry := NewDefaultRegistry()
+	assert.NoError(t, registry.LoadEnv())
+	zkEvm, _ := registry.Get(PolygonZkEvm)
+	assert.Equal(t, "https://zkevm.example.org", zkEvm.Url)
+	devnet, ok := registry.Get("devnet")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1337), devnet.ChainId)
+	assert.Equal(t, []string{"http://localhost:8545", "http://localhost:8546"}, devnet.Endpoints())
+	assert.Equal(t, &core.Limits{RequestsPerSecond: 12.5, MaxConcurrent: 4}, devnet.Limits)
+}
@@ -0,0 +1,81 @@
+package call
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/depocket/multicall-go/core"
+)
+
+// Point is the result of a call at one sampled block. Time is the sampled time
+// for samples taken by time and nil for samples taken by block.
+type Point struct {
+	Block  *big.Int   `json:"block"`
+	Time   *time.Time `json:"time,omitempty"`
+	Result Result     `json:"result"`
+}
+
+// Series holds the points of every call key in the order they were sampled.
+type Series map[string][]Point
+
+// SampleBlocks executes the pending calls at every block, at most concurrency
+// blocks at a time, and returns the results of each key as a series. Reads at
+// a block number use the cache set WithCache.
+func (ct *Contract) SampleBlocks(ctx context.Context, requireSuccess bool, blocks []*big.Int, concurrency int) (Series, error) {
+	return ct.sample(ctx, requireSuccess, blocks, nil, concurrency)
+}
+
+// SampleTimes is SampleBlocks at the last block mined at or before every time,
+// such as the times of core.DailyTimes. Finding the blocks reads block headers,
+// which contracts built WithClient cannot, so they fail with
+// core.ErrBlockHeaderUnsupported.
+func (ct *Contract) SampleTimes(ctx context.Context, requireSuccess bool, times []time.Time, concurrency int) (Series, error) {
+	if !ct.readsHeaders() {
+		ct.ClearCall()
+		return nil, core.ErrBlockHeaderUnsupported
+	}
+	multiCaller, err := ct.caller(ctx)
+	if err != nil {
+		ct.ClearCall()
+		return nil, err
+	}
+	finder := core.NewBlockFinder(multiCaller.Client)
+	blocks := make([]*big.Int, len(times))
+	for i, at := range times {
+		header, err := finder.BlockAt(ctx, at)
+		if err != nil {
+			ct.ClearCall()
+			return nil, err
+		}
+		blocks[i] = header.Number
+	}
+	return ct.sample(ctx, requireSuccess, blocks, times, concurrency)
+}
+
+func (ct *Contract) sample(ctx context.Context, requireSuccess bool, blocks []*big.Int, times []time.Time, concurrency int) (Series, error) {
+	defer ct.ClearCall()
+	multiCaller, err := ct.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := multiCaller.Sample(ctx, ct.calls, requireSuccess, blocks, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	series := make(Series, len(ct.calls))
+	for i, results := range samples {
+		res, err := ct.decode(results)
+		if err != nil {
+			return nil, err
+		}
+		var at *time.Time
+		if times != nil {
+			at = &times[i]
+		}
+		for _, call := range ct.calls {
+			series[call.Key] = append(series[call.Key], Point{Block: blocks[i], Time: at, Result: res[call.Key]})
+		}
+	}
+	return series, nil
+}
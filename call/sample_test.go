@@ -0,0 +1,86 @@
+package call
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/depocket/multicall-go/core"
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// newHeaderNode serves a chain at block 100 mined every 12 seconds, whose
+// multicall answers 42 to every call.
+func newHeaderNode(t *testing.T) *testnode.Node {
+	return testnode.New(t).
+		Multicall(100, func(target common.Address, data []byte) (bool, []byte) {
+			return true, common.LeftPadBytes([]byte{42}, 32)
+		}).
+		Handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+			number := uint64(100)
+			var tag string
+			if err := json.Unmarshal(params[0], &tag); err == nil && tag != "latest" {
+				number = hexutil.MustDecodeUint64(tag)
+			}
+			return map[string]interface{}{
+				"number":    hexutil.Uint64(number),
+				"hash":      common.BigToHash(new(big.Int).SetUint64(0xa000 + number)),
+				"timestamp": hexutil.Uint64(1600000000 + 12*number),
+			}, nil
+		})
+}
+
+func newRPCContract(t *testing.T, node *testnode.Node) *Contract {
+	client, err := rpc.DialHTTP(node.URL)
+	assert.NoError(t, err)
+	contract := NewContractBuilder().WithRPCClient(client).AddMethod("totalSupply()(uint256)")
+	contract.AddCall("supply", "0xdAC17F958D2ee523a2206206994597C13D831ec7", "totalSupply")
+	return contract
+}
+
+func TestContract_HeadersWithClient(t *testing.T) {
+	client, err := ethclient.Dial("http://127.0.0.1:1")
+	assert.NoError(t, err)
+	contract := NewContractBuilder().WithClient(client).AddMethod("totalSupply()(uint256)")
+	contract.AddCall("supply", "0xdAC17F958D2ee523a2206206994597C13D831ec7", "totalSupply")
+
+	_, err = contract.SampleTimes(context.Background(), false, []time.Time{time.Now()}, 1)
+	assert.ErrorIs(t, err, core.ErrBlockHeaderUnsupported)
+
+	contract.AddCall("supply", "0xdAC17F958D2ee523a2206206994597C13D831ec7", "totalSupply")
+	_, _, err = contract.FlexibleCallWithBlock(context.Background(), false, core.LatestBlock)
+	assert.ErrorIs(t, err, core.ErrBlockHeaderUnsupported)
+}
+
+func TestContract_FlexibleCallWithBlockRPCClient(t *testing.T) {
+	node := newHeaderNode(t)
+	defer node.Close()
+	contract := newRPCContract(t, node)
+
+	block, results, err := contract.FlexibleCallWithBlock(context.Background(), false, core.LatestBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, core.Block{Number: big.NewInt(100), Hash: common.BigToHash(big.NewInt(0xa064))}, block)
+	assert.Equal(t, big.NewInt(42), results["supply"].ReturnData[0])
+}
+
+func TestContract_SampleTimesRPCClient(t *testing.T) {
+	node := newHeaderNode(t)
+	defer node.Close()
+	contract := newRPCContract(t, node)
+
+	at := time.Unix(1600000000+12*90+5, 0)
+	series, err := contract.SampleTimes(context.Background(), false, []time.Time{at}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, series["supply"], 1)
+	point := series["supply"][0]
+	assert.Equal(t, big.NewInt(90), point.Block)
+	assert.Equal(t, at, *point.Time)
+	assert.Equal(t, big.NewInt(42), point.Result.ReturnData[0])
+}
@@ -0,0 +1,108 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Calls of each multicall version with an empty call list, newest first.
+var versionProbes = []struct {
+	version  MultiCallVersion
+	callData []byte
+}{
+	{MultiCallV3, hexutil.MustDecode("0x82ad56cb" + word(0x20) + word(0))},
+	{MultiCallV2, hexutil.MustDecode("0xbce38bd7" + word(0) + word(0x40) + word(0))},
+	{MultiCallV1, hexutil.MustDecode("0x252dba42" + word(0x20) + word(0))},
+}
+
+type ChainIdMismatchError struct {
+	Url      string
+	Expected uint64
+	Actual   uint64
+}
+
+func (e *ChainIdMismatchError) Error() string {
+	return fmt.Sprintf("%s serves chain id %d, expected %d", e.Url, e.Actual, e.Expected)
+}
+
+type NoMultiCallCodeError struct {
+	Url     string
+	Address common.Address
+}
+
+func (e *NoMultiCallCodeError) Error() string {
+	return fmt.Sprintf("no contract code at multicall address %s on %s", e.Address.Hex(), e.Url)
+}
+
+type Verification struct {
+	ChainId          uint64
+	MultiCallVersion MultiCallVersion
+}
+
+// Connect dials the primary endpoint of config and verifies it.
+func Connect(ctx context.Context, config ChainConfig) (*ethclient.Client, *Verification, error) {
+	endpoints := config.Endpoints()
+	if config.MultiCallAddress == "" || len(endpoints) == 0 {
+		return nil, nil, fmt.Errorf("invalid configuration, MultiCallAddress and Url must be set")
+	}
+	client, err := ethclient.DialContext(ctx, endpoints[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	verification, err := Verify(ctx, client, endpoints[0], config)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	return client, verification, nil
+}
+
+// Verify checks that client serves the chain id of config, when it is set, and
+// that code is deployed at the multicall address, then detects its version.
+func Verify(ctx context.Context, client *ethclient.Client, url string, config ChainConfig) (*Verification, error) {
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config.ChainId != 0 && chainId.Uint64() != config.ChainId {
+		return nil, &ChainIdMismatchError{Url: url, Expected: config.ChainId, Actual: chainId.Uint64()}
+	}
+	address := common.HexToAddress(config.MultiCallAddress)
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, &NoMultiCallCodeError{Url: url, Address: address}
+	}
+	version, err := DetectMultiCallVersion(ctx, client, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Verification{ChainId: chainId.Uint64(), MultiCallVersion: version}, nil
+}
+
+// DetectMultiCallVersion probes the newest entry point each multicall version
+// provides. It returns MultiCallUnknown when none of them answers.
+func DetectMultiCallVersion(ctx context.Context, caller ethereum.ContractCaller, address common.Address) (MultiCallVersion, error) {
+	for _, probe := range versionProbes {
+		data, err := caller.CallContract(ctx, ethereum.CallMsg{To: &address, Data: probe.callData}, nil)
+		if err == nil && len(data) > 0 {
+			return probe.version, nil
+		}
+		if err != nil && ctx.Err() != nil {
+			return MultiCallUnknown, ctx.Err()
+		}
+	}
+	return MultiCallUnknown, nil
+}
+
+func word(value int64) string {
+	return fmt.Sprintf("%064x", big.NewInt(value))
+}
@@ -0,0 +1,71 @@
+package call
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/stretchr/testify/assert"
+)
+
+// newVerifyNode starts a node for chain id 1 where the multicall contract only
+// answers the entry points of the given version.
+func newVerifyNode(t *testing.T, code string, version MultiCallVersion) *testnode.Node {
+	selectors := map[MultiCallVersion]string{MultiCallV1: "0x252dba42", MultiCallV2: "0xbce38bd7", MultiCallV3: "0x82ad56cb"}
+	return testnode.New(t).
+		Result("eth_chainId", "0x1").
+		Result("eth_getCode", code).
+		Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+			for v := version; v >= MultiCallV1; v-- {
+				if strings.Contains(string(params[0]), selectors[v]) {
+					return "0x" + word(0x20) + word(0), nil
+				}
+			}
+			return nil, &testnode.Error{Code: -32000, Message: "execution reverted"}
+		})
+}
+
+func TestConnect(t *testing.T) {
+	node := newVerifyNode(t, "0x6080", MultiCallV2)
+	defer node.Close()
+
+	client, verification, err := Connect(context.Background(), ChainConfig{ChainId: 1, MultiCallAddress: "0x01", Url: node.URL})
+	assert.NoError(t, err)
+	defer client.Close()
+	assert.Equal(t, uint64(1), verification.ChainId)
+	assert.Equal(t, MultiCallV2, verification.MultiCallVersion)
+
+	_, _, err = Connect(context.Background(), ChainConfig{ChainId: 56, MultiCallAddress: "0x01", Url: node.URL})
+	var mismatch *ChainIdMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, uint64(1), mismatch.Actual)
+}
+
+func TestConnect_NoCode(t *testing.T) {
+	node := newVerifyNode(t, "0x", MultiCallV3)
+	defer node.Close()
+
+	_, _, err := Connect(context.Background(), ChainConfig{MultiCallAddress: "0x01", Url: node.URL})
+	var noCode *NoMultiCallCodeError
+	assert.ErrorAs(t, err, &noCode)
+}
+
+func TestContract_MultiCallV1(t *testing.T) {
+	node := newVerifyNode(t, "0x6080", MultiCallV1)
+	defer node.Close()
+	pool := NewClientPool()
+	defer pool.Close()
+
+	contract, err := NewContractBuilder().WithPool(pool).WithVerifiedChainConfig(context.Background(), ChainConfig{ChainId: 1, MultiCallAddress: "0x01", Url: node.URL})
+	assert.NoError(t, err)
+	caller, err := contract.caller(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, caller.AggregateOnly)
+
+	// another multicall contract has an unknown version
+	caller, err = contract.AtAddress("0x02").Build().caller(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, caller.AggregateOnly)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/core"
+	"github.com/depocket/multicall-go/proxy"
+)
+
+func main() {
+	addr := flag.String("addr", ":8545", "listen address")
+	chain := flag.String("chain", string(call.Ethereum), "chain name from the chain registry")
+	rpcUrl := flag.String("rpc", "", "upstream RPC URL, replaces the chain URLs")
+	multiCallAddress := flag.String("multicall", "", "multicall contract address, overrides the chain address")
+	window := flag.Duration("window", 10*time.Millisecond, "how long eth_call requests are collected before a batch is sent")
+	maxBatch := flag.Int("max-batch", 500, "maximum number of calls per batch")
+	flag.Parse()
+
+	if err := call.DefaultRegistry.LoadEnv(); err != nil {
+		log.Fatal(err)
+	}
+	config, _ := call.DefaultRegistry.Get(call.Chain(*chain))
+	if *rpcUrl != "" {
+		config.Url, config.Urls = *rpcUrl, nil
+	}
+	if *multiCallAddress != "" {
+		config.MultiCallAddress = *multiCallAddress
+	}
+	endpoints := config.Endpoints()
+	if len(endpoints) == 0 || config.MultiCallAddress == "" {
+		log.Fatalf("unknown chain %q, use -rpc and -multicall for a custom chain", *chain)
+	}
+
+	// batches fail over between every chain endpoint, other requests are
+	// forwarded to the primary one
+	caller, err := call.NewContractBuilder().WithChainConfig(config).MultiCaller(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("multicall proxy for %s listening on %s", endpoints[0], *addr)
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           proxy.New(endpoints[0], core.NewBatcher(caller, *window, *maxBatch)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Fatal(httpServer.ListenAndServe())
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	maxCalls := flag.Int("max-calls", 1000, "maximum number of calls per batch, 0 for no limit")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of a batch execution")
+	allowRpc := flag.String("allow-rpc", "", "comma separated rpc URLs that requests may select")
+	flag.Parse()
+	if err := call.DefaultRegistry.LoadEnv(); err != nil {
+		log.Fatal(err)
+	}
+
+	options := server.Options{MaxCalls: *maxCalls, Timeout: *timeout}
+	if *allowRpc != "" {
+		options.AllowedRpcs = strings.Split(*allowRpc, ",")
+	}
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server.NewHandler(options),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// ListenAndServe returns as soon as Shutdown starts, which then waits for
+	// the requests in flight; the pool is closed once they are done
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("shutdown: %v", err)
+		}
+	}()
+
+	log.Printf("multicall server listening on %s", *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	<-drained
+	call.DefaultPool.Close()
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/core"
+	"github.com/depocket/multicall-go/serialize"
+	"github.com/depocket/multicall-go/spec"
+)
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "multicall:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(arguments []string, stdout io.Writer) (err error) {
+	defer call.DefaultPool.Close()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	flags := flag.NewFlagSet("multicall", flag.ContinueOnError)
+	chain := flags.String("chain", string(call.Ethereum), "chain name from the chain registry")
+	rpcUrl := flags.String("rpc", "", "custom RPC URL, replaces the chain URLs")
+	multiCallAddress := flags.String("multicall", "", "multicall contract address, overrides the chain address")
+	block := flags.String("block", "", "block to read at: latest, pending, safe, finalized, a number, or a hash (suffix ! to require it canonical)")
+	strict := flags.Bool("strict", false, "fail the whole batch when any call reverts")
+	format := flags.String("format", "table", "output format: table, json, ndjson or csv")
+	specFile := flags.String("spec", "", "JSON or YAML batch spec file, replaces the call flags")
+	cacheDir := flags.String("cache", "", "directory caching results of reads at a block number or hash")
+	args := flags.String("args", "", "comma separated arguments passed to every signature for every target")
+	var signatures, calls stringList
+	flags.Var(&signatures, "sig", "method signature such as 'balanceOf(address)(uint256)', repeatable")
+	flags.Var(&calls, "call", "explicit call as [key=]target:method[:arg,...], repeatable")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: multicall [flags] [target ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if err := call.DefaultRegistry.LoadEnv(); err != nil {
+		return err
+	}
+	if *specFile != "" {
+		formatSet := false
+		flags.Visit(func(f *flag.Flag) {
+			formatSet = formatSet || f.Name == "format"
+		})
+		return runSpec(*specFile, *format, formatSet, stdout)
+	}
+	if len(signatures) == 0 {
+		return errors.New("at least one -sig is required")
+	}
+
+	config, ok := call.DefaultRegistry.Get(call.Chain(*chain))
+	if !ok && *rpcUrl == "" {
+		return fmt.Errorf("unknown chain %q, use -rpc and -multicall for a custom chain", *chain)
+	}
+	if *rpcUrl != "" {
+		config.Url, config.Urls = *rpcUrl, nil
+	}
+	if *multiCallAddress != "" {
+		config.MultiCallAddress = *multiCallAddress
+	}
+
+	blockRef, err := core.ParseBlockRef(*block)
+	if err != nil {
+		return err
+	}
+
+	contract := call.NewContractBuilder().WithChainConfig(config)
+	if *cacheDir != "" {
+		cache, err := core.NewDiskCache(*cacheDir)
+		if err != nil {
+			return err
+		}
+		contract.WithCache(cache, 0)
+	}
+	methods := make([]string, 0, len(signatures))
+	for _, signature := range signatures {
+		contract.AddMethod(signature)
+		methods = append(methods, call.MethodName(signature))
+	}
+
+	for _, target := range flags.Args() {
+		for _, method := range methods {
+			key := target
+			if len(methods) > 1 {
+				key = target + ":" + method
+			}
+			if err := addCall(contract, key, target, method, call.SplitArgs(*args)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, explicit := range calls {
+		key, target, method, callArgs, err := parseCall(explicit)
+		if err != nil {
+			return err
+		}
+		if err := addCall(contract, key, target, method, callArgs); err != nil {
+			return err
+		}
+	}
+	if len(flags.Args()) == 0 && len(calls) == 0 {
+		return errors.New("no targets or -call given")
+	}
+
+	var results map[string]call.Result
+	if *strict {
+		_, callResults, err := contract.CallAtBlock(context.Background(), blockRef)
+		if err != nil {
+			return err
+		}
+		results = serialize.FromCall(callResults)
+	} else {
+		results, err = contract.FlexibleCallAtBlock(context.Background(), false, blockRef)
+		if err != nil {
+			return err
+		}
+	}
+	return write(stdout, *format, results)
+}
+
+func runSpec(path string, format string, formatSet bool, stdout io.Writer) error {
+	batch, err := spec.Load(path)
+	if err != nil {
+		return err
+	}
+	if !formatSet && batch.Output != "" {
+		format = batch.Output
+	}
+	results, execErr := batch.Execute(context.Background())
+	if results == nil {
+		return execErr
+	}
+	if err := write(stdout, format, results); err != nil {
+		return err
+	}
+	return execErr
+}
+
+func addCall(contract *call.Contract, key string, target string, method string, args []string) error {
+	abiMethod, ok := contract.Abi().Methods[method]
+	if !ok {
+		return fmt.Errorf("method %s has no -sig", method)
+	}
+	values, err := call.ParseArgs(abiMethod, args)
+	if err != nil {
+		return fmt.Errorf("call %s: %w", key, err)
+	}
+	contract.AddCall(key, target, method, values...)
+	return nil
+}
+
+func parseCall(value string) (string, string, string, []string, error) {
+	key := ""
+	if eq := strings.Index(value, "="); eq >= 0 && eq < strings.Index(value, ":") {
+		key, value = value[:eq], value[eq+1:]
+	}
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", nil, fmt.Errorf("invalid -call %q, expected [key=]target:method[:arg,...]", value)
+	}
+	if key == "" {
+		key = parts[0] + ":" + parts[1]
+	}
+	var args []string
+	if len(parts) == 3 {
+		args = call.SplitArgs(parts[2])
+	}
+	return key, parts[0], parts[1], args, nil
+}
+
+func write(w io.Writer, format string, results map[string]call.Result) error {
+	switch format {
+	case "json":
+		return serialize.WriteJSON(w, results)
+	case "ndjson":
+		return serialize.WriteNDJSON(w, results)
+	case "csv":
+		return serialize.WriteCSV(w, results)
+	case "table":
+		return writeTable(w, results)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeTable(w io.Writer, results map[string]call.Result) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "KEY\tSUCCESS\tRESULT")
+	for _, record := range serialize.Records(results) {
+		values := make([]string, 0, len(record.ReturnData))
+		for _, value := range record.ReturnData {
+			if text, ok := value.(string); ok {
+				values = append(values, text)
+				continue
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			values = append(values, string(data))
+		}
+		fmt.Fprintf(table, "%s\t%t\t%s\n", record.Key, record.Success, strings.Join(values, " "))
+	}
+	return table.Flush()
+}
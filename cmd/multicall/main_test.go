@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCall(t *testing.T) {
+	tests := []struct {
+		value  string
+		key    string
+		target string
+		method string
+		args   []string
+	}{
+		{"0x01:totalSupply", "0x01:totalSupply", "0x01", "totalSupply", nil},
+		{"supply=0x01:totalSupply", "supply", "0x01", "totalSupply", nil},
+		{"b=0x01:balanceOf:0x02", "b", "0x01", "balanceOf", []string{"0x02"}},
+		{`s=0x01:swap:[["0x02",1],["0x03",2]],[1,2],"a,b:c"`, "s", "0x01", "swap", []string{`[["0x02",1],["0x03",2]]`, "[1,2]", `"a,b:c"`}},
+		{`0x01:set:{"key":"a=b","values":[1,2]}`, "0x01:set", "0x01", "set", []string{`{"key":"a=b","values":[1,2]}`}},
+	}
+	for _, test := range tests {
+		key, target, method, args, err := parseCall(test.value)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.key, key, test.value)
+		assert.Equal(t, test.target, target, test.value)
+		assert.Equal(t, test.method, method, test.value)
+		assert.Equal(t, test.args, args, test.value)
+	}
+	for _, value := range []string{"", "0x01", "key=0x01", ":totalSupply", "0x01:"} {
+		_, _, _, _, err := parseCall(value)
+		assert.Error(t, err, value)
+	}
+}
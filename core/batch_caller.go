@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/depocket/multicall-go/utils"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var _ BlockCaller = (*BatchCaller)(nil)
+
+// RevertError is returned for a batched call that reverted. It implements the
+// ErrorData method of rpc.DataError like the error of a plain eth_call.
+type RevertError struct {
+	Data []byte
+}
+
+func (e *RevertError) Error() string {
+	if reason := utils.RevertReason(e.Data); reason != "" && reason != hexutil.Encode(e.Data) {
+		return "execution reverted: " + reason
+	}
+	return "execution reverted"
+}
+
+func (e *RevertError) ErrorData() interface{} {
+	return hexutil.Encode(e.Data)
+}
+
+// BatchCaller is a bind.ContractCaller that collects concurrent CallContract
+// invocations into multicall batches, so abigen bindings get batching for free.
+// Calls that set a sender or a value cannot run inside a multicall and go to
+// the client directly.
+type BatchCaller struct {
+	caller  *MultiCaller
+	batcher *Batcher
+}
+
+func NewBatchCaller(caller *MultiCaller, window time.Duration, maxSize int) *BatchCaller {
+	return &BatchCaller{
+		caller:  caller,
+		batcher: NewBatcher(caller, window, maxSize),
+	}
+}
+
+func (c *BatchCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.caller.Client.CodeAt(ctx, contract, blockNumber)
+}
+
+func (c *BatchCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.CallContractAtBlock(ctx, call, BlockNumber(blockNumber))
+}
+
+func (c *BatchCaller) CallContractAtBlock(ctx context.Context, call ethereum.CallMsg, block BlockRef) ([]byte, error) {
+	if call.To == nil || call.From != (common.Address{}) || (call.Value != nil && call.Value.Sign() != 0) {
+		return CallAtBlock(ctx, c.caller.Client, call, block)
+	}
+	response, err := c.batcher.CallAt(ctx, *call.To, call.Data, block)
+	if err != nil {
+		return nil, err
+	}
+	if !response.Status {
+		return nil, &RevertError{Data: response.ReturnData}
+	}
+	return response.ReturnData, nil
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testMultiCallAddress = common.HexToAddress("0x5BA1e12693Dc8F9c48aAD8770482f4739bEeD696")
+	testRevertingTarget  = common.HexToAddress("0x000000000000000000000000000000000000dead")
+)
+
+func TestBatchCaller_CallContract(t *testing.T) {
+	node := testnode.New(t).Multicall(100, testnode.Echo(testRevertingTarget, nil))
+	defer node.Close()
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+	batchCaller := NewBatchCaller(caller, 20*time.Millisecond, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := common.BigToAddress(common.Big1)
+			data, err := batchCaller.CallContract(context.Background(), ethereum.CallMsg{To: &target, Data: []byte{byte(i)}}, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte{byte(i)}, data)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, node.Requests("eth_call"))
+
+	_, err = batchCaller.CallContract(context.Background(), ethereum.CallMsg{To: &testRevertingTarget}, nil)
+	var revertErr *RevertError
+	assert.ErrorAs(t, err, &revertErr)
+	assert.Equal(t, "execution reverted", err.Error())
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Batcher coalesces single calls arriving within a short window into one
+// tryAggregate per block, dataloader style.
+type Batcher struct {
+	caller  *MultiCaller
+	window  time.Duration
+	maxSize int
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingBatch
+}
+
+type pendingBatch struct {
+	block    BlockRef
+	requests []*batchRequest
+	timer    *time.Timer
+}
+
+type batchRequest struct {
+	call     MultiCall
+	response CallResponse
+	err      error
+	done     chan struct{}
+}
+
+func NewBatcher(caller *MultiCaller, window time.Duration, maxSize int) *Batcher {
+	return &Batcher{
+		caller:  caller,
+		window:  window,
+		maxSize: maxSize,
+		timeout: 30 * time.Second,
+		pending: make(map[string]*pendingBatch),
+	}
+}
+
+// Call queues a call at blockNumber, nil meaning latest, and waits for the
+// batch it joined. A reverted call is returned with Status false and the revert
+// data as ReturnData.
+func (b *Batcher) Call(ctx context.Context, target common.Address, data []byte, blockNumber *big.Int) (CallResponse, error) {
+	return b.CallAt(ctx, target, data, BlockNumber(blockNumber))
+}
+
+// CallAt is Call at any BlockRef, calls are batched per BlockRef.
+func (b *Batcher) CallAt(ctx context.Context, target common.Address, data []byte, block BlockRef) (CallResponse, error) {
+	request := &batchRequest{
+		call: MultiCall{Target: target, CallData: data},
+		done: make(chan struct{}),
+	}
+	b.enqueue(request, block)
+	select {
+	case <-request.done:
+		return request.response, request.err
+	case <-ctx.Done():
+		return CallResponse{}, ctx.Err()
+	}
+}
+
+func (b *Batcher) enqueue(request *batchRequest, block BlockRef) {
+	key := block.String()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batch, ok := b.pending[key]
+	if !ok {
+		batch = &pendingBatch{block: block}
+		b.pending[key] = batch
+		batch.timer = time.AfterFunc(b.window, func() {
+			b.flush(key, batch)
+		})
+	}
+	batch.requests = append(batch.requests, request)
+	if b.maxSize > 0 && len(batch.requests) >= b.maxSize {
+		batch.timer.Stop()
+		delete(b.pending, key)
+		go b.execute(batch)
+	}
+}
+
+func (b *Batcher) flush(key string, batch *pendingBatch) {
+	b.mu.Lock()
+	if b.pending[key] != batch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, key)
+	b.mu.Unlock()
+	b.execute(batch)
+}
+
+func (b *Batcher) execute(batch *pendingBatch) {
+	calls := make([]Call, len(batch.requests))
+	for i, request := range batch.requests {
+		calls[i] = Call{
+			Key:      strconv.Itoa(i),
+			Target:   request.call.Target,
+			CallData: request.call.CallData,
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	results, err := b.caller.ExecuteAtBlock(ctx, calls, false, batch.block)
+	for i, request := range batch.requests {
+		if err != nil {
+			request.err = err
+		} else {
+			request.response = results[calls[i].Key]
+		}
+		close(request.done)
+	}
+}
@@ -0,0 +1,262 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrBlockRefUnsupported = errors.New("client cannot read at this block")
+
+// BlockRef selects the state a call reads: a block tag, a block number, or a
+// block hash as in EIP-1898. The zero BlockRef is the latest block.
+type BlockRef struct {
+	tag              string
+	number           *big.Int
+	hash             common.Hash
+	requireCanonical bool
+}
+
+var (
+	LatestBlock    = BlockRef{}
+	PendingBlock   = BlockRef{tag: "pending"}
+	SafeBlock      = BlockRef{tag: "safe"}
+	FinalizedBlock = BlockRef{tag: "finalized"}
+)
+
+// BlockNumber refers to a block by number. A nil number is the latest block
+// and -1 the pending one, as with ethclient.
+func BlockNumber(number *big.Int) BlockRef {
+	switch {
+	case number == nil:
+		return LatestBlock
+	case number.Sign() < 0:
+		return PendingBlock
+	}
+	return BlockRef{number: new(big.Int).Set(number)}
+}
+
+// BlockHash refers to a block by hash. With requireCanonical the node refuses
+// the call when the block is no longer part of the canonical chain.
+func BlockHash(hash common.Hash, requireCanonical bool) BlockRef {
+	return BlockRef{hash: hash, requireCanonical: requireCanonical}
+}
+
+// ParseBlockRef reads a tag, a decimal or hex block number, or a 32 byte block
+// hash. A hash followed by "!" requires the block to be canonical.
+func ParseBlockRef(value string) (BlockRef, error) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "", "latest":
+		return LatestBlock, nil
+	case "pending":
+		return PendingBlock, nil
+	case "safe":
+		return SafeBlock, nil
+	case "finalized":
+		return FinalizedBlock, nil
+	}
+	canonical := strings.HasSuffix(value, "!")
+	hash := strings.TrimSuffix(value, "!")
+	if strings.HasPrefix(hash, "0x") && len(hash) == 66 {
+		bytes, err := hexutil.Decode(hash)
+		if err != nil {
+			return BlockRef{}, fmt.Errorf("invalid block hash %q", value)
+		}
+		return BlockHash(common.BytesToHash(bytes), canonical), nil
+	}
+	number, ok := new(big.Int).SetString(value, 0)
+	if !ok || number.Sign() < 0 {
+		return BlockRef{}, fmt.Errorf("invalid block %q", value)
+	}
+	return BlockNumber(number), nil
+}
+
+func (b BlockRef) IsLatest() bool {
+	return b.tag == "" && b.number == nil && b.hash == (common.Hash{})
+}
+
+// Number returns the block number of a BlockRef that refers to one.
+func (b BlockRef) Number() (*big.Int, bool) {
+	if b.number == nil {
+		return nil, false
+	}
+	return new(big.Int).Set(b.number), true
+}
+
+// Hash returns the block hash of a BlockRef that refers to one.
+func (b BlockRef) Hash() (common.Hash, bool) {
+	return b.hash, b.hash != (common.Hash{})
+}
+
+func (b BlockRef) RequireCanonical() bool {
+	return b.requireCanonical
+}
+
+// Immutable reports whether the state read at b can never change, which holds
+// for block numbers and hashes but not for tags.
+func (b BlockRef) Immutable() bool {
+	return b.number != nil || b.hash != (common.Hash{})
+}
+
+func (b BlockRef) String() string {
+	switch {
+	case b.number != nil:
+		return b.number.String()
+	case b.hash != (common.Hash{}):
+		if b.requireCanonical {
+			return b.hash.Hex() + "!"
+		}
+		return b.hash.Hex()
+	case b.tag != "":
+		return b.tag
+	}
+	return "latest"
+}
+
+func (b BlockRef) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *BlockRef) UnmarshalText(text []byte) error {
+	ref, err := ParseBlockRef(string(text))
+	if err != nil {
+		return err
+	}
+	*b = ref
+	return nil
+}
+
+// UnmarshalJSON reads a string as UnmarshalText does, or a block number.
+func (b *BlockRef) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return fmt.Errorf("invalid block %s", data)
+		}
+		text = number.String()
+	}
+	return b.UnmarshalText([]byte(text))
+}
+
+// Arg is the block parameter of JSON-RPC methods such as eth_call.
+func (b BlockRef) Arg() interface{} {
+	switch {
+	case b.number != nil:
+		return hexutil.EncodeBig(b.number)
+	case b.hash != (common.Hash{}):
+		return map[string]interface{}{"blockHash": b.hash, "requireCanonical": b.requireCanonical}
+	}
+	return b.String()
+}
+
+// BlockCaller is a client that reads at any BlockRef.
+type BlockCaller interface {
+	bind.ContractCaller
+	CallContractAtBlock(ctx context.Context, call ethereum.CallMsg, block BlockRef) ([]byte, error)
+}
+
+// CallAtBlock calls through client at block. Clients that are no BlockCaller
+// can only read the latest and pending blocks and block numbers.
+func CallAtBlock(ctx context.Context, client bind.ContractCaller, call ethereum.CallMsg, block BlockRef) ([]byte, error) {
+	if blockCaller, ok := client.(BlockCaller); ok {
+		return blockCaller.CallContractAtBlock(ctx, call, block)
+	}
+	switch {
+	case block.IsLatest():
+		return client.CallContract(ctx, call, nil)
+	case block == PendingBlock:
+		return client.CallContract(ctx, call, big.NewInt(-1))
+	case block.number != nil:
+		return client.CallContract(ctx, call, block.number)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrBlockRefUnsupported, block)
+}
+
+var _ BlockCaller = (*RPCClient)(nil)
+
+// RPCClient sends eth_call and eth_getCode over a JSON-RPC connection with the
+// block parameter of a BlockRef.
+type RPCClient struct {
+	client *rpc.Client
+}
+
+func NewRPCClient(client *rpc.Client) *RPCClient {
+	return &RPCClient{client: client}
+}
+
+func (c *RPCClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.CallContractAtBlock(ctx, call, BlockNumber(blockNumber))
+}
+
+func (c *RPCClient) CallContractAtBlock(ctx context.Context, call ethereum.CallMsg, block BlockRef) ([]byte, error) {
+	var result hexutil.Bytes
+	err := c.client.CallContext(ctx, &result, "eth_call", callArg(call), block.Arg())
+	return result, err
+}
+
+func (c *RPCClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	var result hexutil.Bytes
+	err := c.client.CallContext(ctx, &result, "eth_getCode", contract, BlockNumber(blockNumber).Arg())
+	return result, err
+}
+
+func callArg(call ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": call.From,
+		"to":   call.To,
+	}
+	if len(call.Data) > 0 {
+		arg["data"] = hexutil.Bytes(call.Data)
+	}
+	if call.Value != nil {
+		arg["value"] = (*hexutil.Big)(call.Value)
+	}
+	if call.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(call.Gas)
+	}
+	if call.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(call.GasPrice)
+	}
+	return arg
+}
+
+// BlockHashAt reads the hash of the canonical block at number.
+func (c *RPCClient) BlockHashAt(ctx context.Context, number *big.Int) (common.Hash, error) {
+	header, err := c.BlockHeaderAt(ctx, BlockNumber(number))
+	return header.Hash, err
+}
+
+// BlockHeaderAt reads the header of block with eth_getBlockByHash or
+// eth_getBlockByNumber. The hash is taken as the node reports it rather than
+// computed from the header, whose fields differ between chains.
+func (c *RPCClient) BlockHeaderAt(ctx context.Context, block BlockRef) (BlockHeader, error) {
+	var head *struct {
+		Number *hexutil.Big   `json:"number"`
+		Hash   common.Hash    `json:"hash"`
+		Time   hexutil.Uint64 `json:"timestamp"`
+	}
+	var err error
+	if hash, ok := block.Hash(); ok {
+		err = c.client.CallContext(ctx, &head, "eth_getBlockByHash", hash, false)
+	} else {
+		err = c.client.CallContext(ctx, &head, "eth_getBlockByNumber", block.Arg(), false)
+	}
+	if err == nil && (head == nil || head.Number == nil) {
+		err = ethereum.NotFound
+	}
+	if err != nil {
+		return BlockHeader{}, err
+	}
+	return BlockHeader{Number: head.Number.ToInt(), Hash: head.Hash, Time: uint64(head.Time)}, nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBlockRef(t *testing.T) {
+	hash := common.HexToHash("0xabcd")
+	tests := []struct {
+		value string
+		want  BlockRef
+		arg   interface{}
+	}{
+		{"", LatestBlock, "latest"},
+		{"latest", LatestBlock, "latest"},
+		{"Finalized", FinalizedBlock, "finalized"},
+		{"safe", SafeBlock, "safe"},
+		{"pending", PendingBlock, "pending"},
+		{"15000000", BlockNumber(big.NewInt(15000000)), "0xe4e1c0"},
+		{"0x10", BlockNumber(big.NewInt(16)), "0x10"},
+		{hash.Hex(), BlockHash(hash, false), map[string]interface{}{"blockHash": hash, "requireCanonical": false}},
+		{hash.Hex() + "!", BlockHash(hash, true), map[string]interface{}{"blockHash": hash, "requireCanonical": true}},
+	}
+	for _, test := range tests {
+		block, err := ParseBlockRef(test.value)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.want, block, test.value)
+		assert.Equal(t, test.arg, block.Arg(), test.value)
+	}
+	for _, value := range []string{"soon", "-1", "0xzz"} {
+		_, err := ParseBlockRef(value)
+		assert.Error(t, err, value)
+	}
+
+	assert.True(t, BlockHash(hash, true).Immutable())
+	assert.True(t, BlockNumber(big.NewInt(1)).Immutable())
+	assert.False(t, FinalizedBlock.Immutable())
+	assert.Equal(t, PendingBlock, BlockNumber(big.NewInt(-1)))
+	assert.Equal(t, LatestBlock, BlockNumber(nil))
+}
+
+func TestBlockRef_UnmarshalJSON(t *testing.T) {
+	var blocks []BlockRef
+	err := json.Unmarshal([]byte(`["finalized", 100, "0x64"]`), &blocks)
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockRef{FinalizedBlock, BlockNumber(big.NewInt(100)), BlockNumber(big.NewInt(100))}, blocks)
+
+	data, err := json.Marshal(blocks)
+	assert.NoError(t, err)
+	assert.Equal(t, `["finalized","100","100"]`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{}`), &blocks[0]))
+}
+
+func TestCallAtBlock(t *testing.T) {
+	client := &aggregateClient{}
+	_, err := CallAtBlock(context.Background(), client, ethereum.CallMsg{}, SafeBlock)
+	assert.ErrorIs(t, err, ErrBlockRefUnsupported)
+	_, err = CallAtBlock(context.Background(), client, ethereum.CallMsg{}, BlockHash(common.HexToHash("0x01"), false))
+	assert.ErrorIs(t, err, ErrBlockRefUnsupported)
+}
+
+func TestRPCClient_CallContractAtBlock(t *testing.T) {
+	var params []json.RawMessage
+	node := testnode.New(t).Handle("eth_call", func(callParams []json.RawMessage) (interface{}, error) {
+		params = callParams
+		return "0x2a", nil
+	})
+	defer node.Close()
+
+	rpcClient, err := rpc.DialHTTP(node.URL)
+	assert.NoError(t, err)
+	defer rpcClient.Close()
+	client := NewRPCClient(rpcClient)
+
+	to := common.HexToAddress("0x01")
+	data, err := client.CallContractAtBlock(context.Background(), ethereum.CallMsg{To: &to, Data: []byte{1}}, FinalizedBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x2a}, data)
+	assert.JSONEq(t, `"finalized"`, string(params[1]))
+
+	hash := common.HexToHash("0xabcd")
+	_, err = client.CallContractAtBlock(context.Background(), ethereum.CallMsg{To: &to}, BlockHash(hash, true))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"blockHash":"`+hash.Hex()+`","requireCanonical":true}`, string(params[1]))
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Cache stores the responses of sub-calls. A zero ttl means the entry never
+// expires, which is what reads at a fixed block use.
+type Cache interface {
+	Get(key string) (CallResponse, bool)
+	Set(key string, response CallResponse, ttl time.Duration)
+}
+
+// CacheKey identifies a sub-call by namespace (usually the chain), block,
+// target and call data.
+func CacheKey(namespace string, block BlockRef, call Call) string {
+	return fmt.Sprintf("%s/%s/%s/%x", namespace, block, call.Target.Hex(), call.CallData)
+}
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func expired(expires time.Time) bool {
+	return !expires.IsZero() && time.Now().After(expires)
+}
+
+// MemoryCache is a concurrency safe LRU cache holding at most size entries.
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key      string
+	response CallResponse
+	expires  time.Time
+}
+
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *MemoryCache) Get(key string) (CallResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return CallResponse{}, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if expired(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return CallResponse{}, false
+	}
+	c.order.MoveToFront(element)
+	return entry.response, true
+}
+
+func (c *MemoryCache) Set(key string, response CallResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value = &memoryEntry{key: key, response: response, expires: expiry(ttl)}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, response: response, expires: expiry(ttl)})
+	for c.size > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// DiskCache stores one JSON file per entry in a directory, named by the hash of
+// the key, so cached historical reads survive restarts.
+type DiskCache struct {
+	dir string
+}
+
+type diskEntry struct {
+	Key        string        `json:"key"`
+	Status     bool          `json:"status"`
+	ReturnData hexutil.Bytes `json:"returnData"`
+	Expires    time.Time     `json:"expires,omitempty"`
+}
+
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name+".json")
+}
+
+func (c *DiskCache) Get(key string) (CallResponse, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return CallResponse{}, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return CallResponse{}, false
+	}
+	if expired(entry.Expires) {
+		_ = os.Remove(path)
+		return CallResponse{}, false
+	}
+	return CallResponse{Status: entry.Status, ReturnData: entry.ReturnData}, true
+}
+
+// Set writes the entry through a temporary file, errors are ignored as a
+// failed write only costs a later cache miss.
+func (c *DiskCache) Set(key string, response CallResponse, ttl time.Duration) {
+	_ = c.set(key, response, ttl)
+}
+
+func (c *DiskCache) set(key string, response CallResponse, ttl time.Duration) error {
+	data, err := json.Marshal(diskEntry{Key: key, Status: response.Status, ReturnData: response.ReturnData, Expires: expiry(ttl)})
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
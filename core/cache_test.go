@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// aggregateClient answers tryAggregate and aggregate by echoing the call data
+// of every sub-call, and records how many sub-calls each request carried.
+type aggregateClient struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (c *aggregateClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	mcAbi, err := abi.JSON(strings.NewReader(MultiMetaData.ABI))
+	if err != nil {
+		return nil, err
+	}
+	method, err := mcAbi.MethodById(call.Data)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := inputs[len(inputs)-1].([]struct {
+		Target   common.Address `json:"target"`
+		CallData []byte         `json:"callData"`
+	})
+	c.mu.Lock()
+	c.batches = append(c.batches, len(calls))
+	c.mu.Unlock()
+	if method.Name == "aggregate" {
+		returnData := make([][]byte, len(calls))
+		for i, call := range calls {
+			returnData[i] = call.CallData
+		}
+		return method.Outputs.Pack(big.NewInt(100), returnData)
+	}
+	results := make([]struct {
+		Success    bool
+		ReturnData []byte
+	}, len(calls))
+	for i, call := range calls {
+		results[i].Success = true
+		results[i].ReturnData = call.CallData
+	}
+	return method.Outputs.Pack(results)
+}
+
+func (c *aggregateClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func cacheCalls(keys ...string) []Call {
+	calls := make([]Call, 0, len(keys))
+	for _, key := range keys {
+		calls = append(calls, Call{Key: key, Method: "echo", Target: common.BigToAddress(common.Big1), CallData: []byte(key)})
+	}
+	return calls
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", CallResponse{Status: true, ReturnData: []byte{1}}, 0)
+	cache.Set("b", CallResponse{Status: true, ReturnData: []byte{2}}, 0)
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	cache.Set("c", CallResponse{Status: true, ReturnData: []byte{3}}, 0)
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+
+	cache.Set("latest", CallResponse{Status: true}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_, ok = cache.Get("latest")
+	assert.False(t, ok)
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	cache.Set("1/100/0x01/abcd", CallResponse{Status: true, ReturnData: []byte{0xab}}, 0)
+	cache.Set("1/latest/0x01/abcd", CallResponse{Status: true}, time.Millisecond)
+
+	reopened, err := NewDiskCache(dir)
+	assert.NoError(t, err)
+	response, ok := reopened.Get("1/100/0x01/abcd")
+	assert.True(t, ok)
+	assert.Equal(t, CallResponse{Status: true, ReturnData: []byte{0xab}}, response)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok = reopened.Get("1/latest/0x01/abcd")
+	assert.False(t, ok)
+	_, ok = reopened.Get("1/101/0x01/abcd")
+	assert.False(t, ok)
+}
+
+// hashClient reads a block hash as the block of that number.
+type hashClient struct {
+	aggregateClient
+}
+
+func (c *hashClient) CallContractAtBlock(ctx context.Context, call ethereum.CallMsg, block BlockRef) ([]byte, error) {
+	if hash, ok := block.Hash(); ok {
+		return c.CallContract(ctx, call, hash.Big())
+	}
+	number, _ := block.Number()
+	return c.CallContract(ctx, call, number)
+}
+
+func TestMultiCaller_CacheStrictAtHash(t *testing.T) {
+	client := &hashClient{}
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+	caller.Cache = NewMemoryCache(100)
+	caller.CacheNamespace = "1"
+
+	block := BlockHash(common.BigToHash(big.NewInt(100)), true)
+	for i := 0; i < 2; i++ {
+		blockNumber, results, err := caller.StrictlyExecuteAt(context.Background(), cacheCalls("a", "b"), block)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(100), blockNumber)
+		assert.Equal(t, []byte("b"), results["b"].ReturnData)
+	}
+	assert.Equal(t, []int{2}, client.batches)
+}
+
+func TestMultiCaller_Cache(t *testing.T) {
+	client := &aggregateClient{}
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+	caller.Cache = NewMemoryCache(100)
+	caller.CacheNamespace = "1"
+
+	block := big.NewInt(100)
+	_, err = caller.ExecuteAt(context.Background(), cacheCalls("a", "b"), false, block)
+	assert.NoError(t, err)
+	results, err := caller.ExecuteAt(context.Background(), cacheCalls("a", "b", "c"), false, block)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), results["a"].ReturnData)
+	assert.Equal(t, "echo", results["a"].Method)
+	assert.Equal(t, []byte("c"), results["c"].ReturnData)
+
+	blockNumber, results, err := caller.StrictlyExecute(cacheCalls("b", "c"), block)
+	assert.NoError(t, err)
+	assert.Equal(t, block, blockNumber)
+	assert.Equal(t, []byte("b"), results["b"].ReturnData)
+	assert.Equal(t, []int{2, 1}, client.batches)
+
+	// latest reads are only cached with a TTL
+	_, err = caller.ExecuteAt(context.Background(), cacheCalls("a"), false, nil)
+	assert.NoError(t, err)
+	_, err = caller.ExecuteAt(context.Background(), cacheCalls("a"), false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 1, 1}, client.batches)
+
+	caller.LatestTTL = time.Minute
+	_, err = caller.ExecuteAt(context.Background(), cacheCalls("a"), false, nil)
+	assert.NoError(t, err)
+	_, err = caller.ExecuteAt(context.Background(), cacheCalls("a"), false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 1, 1, 1}, client.batches)
+}
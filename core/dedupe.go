@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// dedupe keeps the first call of every (target, call data) and returns the
+// later ones by the key of the call they duplicate.
+func dedupe(calls []Call) ([]Call, map[string][]Call) {
+	unique := make([]Call, 0, len(calls))
+	duplicates := make(map[string][]Call)
+	first := make(map[string]string, len(calls))
+	for _, call := range calls {
+		id := call.Target.Hex() + string(call.CallData)
+		if key, ok := first[id]; ok {
+			duplicates[key] = append(duplicates[key], call)
+			continue
+		}
+		first[id] = call.Key
+		unique = append(unique, call)
+	}
+	return unique, duplicates
+}
+
+// fanOut copies the response of every unique call to results, under its own
+// key and under the keys of its duplicates.
+func fanOut(results map[string]CallResponse, unique []Call, duplicates map[string][]Call, responses map[string]CallResponse) {
+	for _, call := range unique {
+		response := responses[call.Key]
+		results[call.Key] = response
+		for _, duplicate := range duplicates[call.Key] {
+			response.Method = duplicate.Method
+			results[duplicate.Key] = response
+		}
+	}
+}
+
+type flight struct {
+	done     chan struct{}
+	response CallResponse
+	err      error
+}
+
+// FlightGroup tracks the sub-calls in flight, so a batch that needs a sub-call
+// another batch already sent for the same block waits for its response instead
+// of sending it again.
+type FlightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+var DefaultFlights = NewFlightGroup()
+
+func NewFlightGroup() *FlightGroup {
+	return &FlightGroup{flights: make(map[string]*flight)}
+}
+
+// start returns the flights started for keys nobody was waiting on yet and the
+// flights already in progress for the other keys.
+func (g *FlightGroup) start(keys []string) (map[string]*flight, map[string]*flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	started := make(map[string]*flight)
+	joined := make(map[string]*flight)
+	for _, key := range keys {
+		if f, ok := g.flights[key]; ok {
+			joined[key] = f
+			continue
+		}
+		f := &flight{done: make(chan struct{})}
+		g.flights[key] = f
+		started[key] = f
+	}
+	return started, joined
+}
+
+func (g *FlightGroup) finish(key string, f *flight, response CallResponse, err error) {
+	g.mu.Lock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	g.mu.Unlock()
+	f.response = response
+	f.err = err
+	close(f.done)
+}
+
+// shared executes calls, joining the flights of sub-calls other batches sent.
+// Sub-calls whose flight failed, or reverted when success is required, are sent
+// again so the error is the one this batch would have had on its own.
+func (caller *MultiCaller) shared(ctx context.Context, calls []Call, requireSuccess bool, block BlockRef) (map[string]CallResponse, error) {
+	if caller.Flights == nil {
+		return caller.tryAggregate(ctx, calls, requireSuccess, block)
+	}
+	keys := make([]string, len(calls))
+	for i, call := range calls {
+		keys[i] = caller.ContractAddress.Hex() + "/" + CacheKey(caller.CacheNamespace, block, call)
+	}
+	started, joined := caller.Flights.start(keys)
+
+	own := make([]Call, 0, len(started))
+	for i, call := range calls {
+		if _, ok := started[keys[i]]; ok {
+			own = append(own, call)
+		}
+	}
+	results := make(map[string]CallResponse, len(calls))
+	var err error
+	if len(own) > 0 {
+		results, err = caller.tryAggregate(ctx, own, requireSuccess, block)
+	}
+	for i, call := range calls {
+		if f, ok := started[keys[i]]; ok {
+			caller.Flights.finish(keys[i], f, results[call.Key], err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	retry := make([]Call, 0)
+	for i, call := range calls {
+		f, ok := joined[keys[i]]
+		if !ok {
+			continue
+		}
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if f.err != nil || (requireSuccess && !f.response.Status) {
+			retry = append(retry, call)
+			continue
+		}
+		response := f.response
+		response.Method = call.Method
+		results[call.Key] = response
+	}
+	if len(retry) > 0 {
+		retried, err := caller.tryAggregate(ctx, retry, requireSuccess, block)
+		if err != nil {
+			return nil, err
+		}
+		for key, response := range retried {
+			results[key] = response
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedClient holds its first request until release is closed.
+type gatedClient struct {
+	aggregateClient
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *gatedClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	first := false
+	c.once.Do(func() { first = true })
+	if first {
+		close(c.started)
+		<-c.release
+	}
+	return c.aggregateClient.CallContract(ctx, call, blockNumber)
+}
+
+func TestMultiCaller_Dedupe(t *testing.T) {
+	client := &aggregateClient{}
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+
+	calls := cacheCalls("a", "b")
+	calls = append(calls, Call{Key: "a-again", Method: "other", Target: calls[0].Target, CallData: calls[0].CallData})
+	results, err := caller.ExecuteAt(context.Background(), calls, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, client.batches)
+	assert.Equal(t, []byte("a"), results["a-again"].ReturnData)
+	assert.Equal(t, "other", results["a-again"].Method)
+
+	_, results, err = caller.StrictlyExecute(calls, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 2}, client.batches)
+	assert.Equal(t, []byte("a"), results["a-again"].ReturnData)
+}
+
+func TestMultiCaller_SingleFlight(t *testing.T) {
+	client := &gatedClient{started: make(chan struct{}), release: make(chan struct{})}
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+	caller.Flights = NewFlightGroup()
+	block := big.NewInt(100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results, err := caller.ExecuteAt(context.Background(), cacheCalls("a", "b"), false, block)
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+	}()
+	<-client.started
+
+	wg.Add(1)
+	var shared map[string]CallResponse
+	go func() {
+		defer wg.Done()
+		var err error
+		shared, err = caller.ExecuteAt(context.Background(), cacheCalls("b", "c"), false, block)
+		assert.NoError(t, err)
+	}()
+	for {
+		client.mu.Lock()
+		sent := len(client.batches)
+		client.mu.Unlock()
+		if sent == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(client.release)
+	wg.Wait()
+
+	sort.Ints(client.batches)
+	assert.Equal(t, []int{1, 2}, client.batches)
+	assert.Equal(t, []byte("b"), shared["b"].ReturnData)
+	assert.Equal(t, []byte("c"), shared["c"].ReturnData)
+}
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var _ BlockCaller = (*FailoverClient)(nil)
+
+// Messages of node errors that another endpoint, or the same one a bit later,
+// may not return: rate limits, overload and nodes lagging behind the block.
+var retryableMessages = []string{
+	"rate limit",
+	"too many requests",
+	"limit exceeded",
+	"timeout",
+	"timed out",
+	"header not found",
+	"missing trie node",
+	"unknown block",
+	"connection reset",
+	"connection refused",
+	"service unavailable",
+	"bad gateway",
+}
+
+type Endpoint struct {
+	Url    string
+	Client bind.ContractCaller
+}
+
+// RetryPolicy bounds the attempts of one call across all endpoints. The delay
+// before a retry grows exponentially from BaseDelay up to MaxDelay, with full jitter.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+type EndpointError struct {
+	Url string
+	Err error
+}
+
+func (e *EndpointError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Url, e.Err)
+}
+
+func (e *EndpointError) Unwrap() error {
+	return e.Err
+}
+
+// FailoverClient sends each request to its endpoints in order, moving to the
+// next endpoint after a retryable error. The block number of a call is passed
+// unchanged to every attempt, so a block-pinned call reads the same state
+// whichever endpoint answers it. With a HealthTracker, endpoints with an open
+// circuit are skipped and the others are tried healthiest first.
+type FailoverClient struct {
+	endpoints []Endpoint
+	policy    RetryPolicy
+	health    *HealthTracker
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func NewFailoverClient(endpoints []Endpoint, policy RetryPolicy) *FailoverClient {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	return &FailoverClient{
+		endpoints: endpoints,
+		policy:    policy,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (c *FailoverClient) WithHealth(health *HealthTracker) *FailoverClient {
+	for _, endpoint := range c.endpoints {
+		health.Track(endpoint.Url, endpoint.Client)
+	}
+	c.health = health
+	return c
+}
+
+func (c *FailoverClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (data []byte, err error) {
+	err = c.do(ctx, func(client bind.ContractCaller) error {
+		data, err = client.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return data, err
+}
+
+func (c *FailoverClient) CallContractAtBlock(ctx context.Context, call ethereum.CallMsg, block BlockRef) (data []byte, err error) {
+	err = c.do(ctx, func(client bind.ContractCaller) error {
+		data, err = CallAtBlock(ctx, client, call, block)
+		return err
+	})
+	return data, err
+}
+
+func (c *FailoverClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = c.do(ctx, func(client bind.ContractCaller) error {
+		code, err = client.CodeAt(ctx, contract, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (c *FailoverClient) BlockHashAt(ctx context.Context, number *big.Int) (hash common.Hash, err error) {
+	err = c.do(ctx, func(client bind.ContractCaller) error {
+		hash, err = BlockHashAt(ctx, client, number)
+		return err
+	})
+	return hash, err
+}
+
+func (c *FailoverClient) BlockHeaderAt(ctx context.Context, block BlockRef) (header BlockHeader, err error) {
+	err = c.do(ctx, func(client bind.ContractCaller) error {
+		header, err = BlockHeaderAt(ctx, client, block)
+		return err
+	})
+	return header, err
+}
+
+// do tries the endpoints in the order they had when the call started, moving
+// a failed endpoint to the back of the queue so every other endpoint is tried
+// before it again. Endpoints whose circuit opens during the call are dropped.
+func (c *FailoverClient) do(ctx context.Context, request func(client bind.ContractCaller) error) error {
+	if len(c.endpoints) == 0 {
+		return errors.New("no endpoints configured")
+	}
+	queue := append([]Endpoint(nil), c.endpoints...)
+	if c.health != nil {
+		queue = c.health.Order(queue)
+	}
+	if len(queue) == 0 {
+		return ErrCircuitOpen
+	}
+	var lastErr error
+	for attempt := 0; attempt < c.policy.MaxAttempts && len(queue) > 0; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return lastErr
+			}
+		}
+		endpoint := queue[0]
+		queue = queue[1:]
+		start := time.Now()
+		err := request(endpoint.Client)
+		if c.health != nil && ctx.Err() == nil {
+			c.health.Record(endpoint.Url, time.Since(start), err)
+		}
+		if err == nil {
+			return nil
+		}
+		lastErr = &EndpointError{Url: endpoint.Url, Err: err}
+		if ctx.Err() != nil || !IsRetryable(err) {
+			return lastErr
+		}
+		if c.health == nil || c.health.Allow(endpoint.Url) {
+			queue = append(queue, endpoint)
+		}
+	}
+	return lastErr
+}
+
+func (c *FailoverClient) backoff(attempt int) time.Duration {
+	delay := c.policy.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (c.policy.MaxDelay > 0 && delay > c.policy.MaxDelay) {
+		delay = c.policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Duration(c.rand.Int63n(int64(delay) + 1))
+}
+
+// IsRetryable reports whether err is transient: a timeout or connection
+// failure, an HTTP 429 or 5xx answer, or a node error such as a rate limit or
+// a missing block. Reverts and other call errors are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "execution reverted") {
+		return false
+	}
+	for _, retryable := range retryableMessages {
+		if strings.Contains(message, retryable) {
+			return true
+		}
+	}
+	return false
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
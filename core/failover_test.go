@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type scriptedClient struct {
+	errors []error
+	calls  int
+	blocks []*big.Int
+}
+
+func (c *scriptedClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls++
+	c.blocks = append(c.blocks, blockNumber)
+	if len(c.errors) > 0 {
+		err := c.errors[0]
+		c.errors = c.errors[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []byte{0x01}, nil
+}
+
+func (c *scriptedClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.CallContract(ctx, ethereum.CallMsg{}, blockNumber)
+}
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestFailoverClient_Failover(t *testing.T) {
+	primary := &scriptedClient{errors: []error{rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}}}
+	secondary := &scriptedClient{errors: []error{errors.New("header not found")}}
+	client := NewFailoverClient([]Endpoint{{Url: "primary", Client: primary}, {Url: "secondary", Client: secondary}}, testRetryPolicy)
+
+	block := big.NewInt(15000000)
+	data, err := client.CallContract(context.Background(), ethereum.CallMsg{}, block)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01}, data)
+	assert.Equal(t, 2, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+	assert.Equal(t, []*big.Int{block, block}, primary.blocks)
+	assert.Equal(t, []*big.Int{block}, secondary.blocks)
+}
+
+func TestFailoverClient_NotRetryable(t *testing.T) {
+	primary := &scriptedClient{errors: []error{errors.New("execution reverted")}}
+	secondary := &scriptedClient{}
+	client := NewFailoverClient([]Endpoint{{Url: "primary", Client: primary}, {Url: "secondary", Client: secondary}}, testRetryPolicy)
+
+	_, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	assert.EqualError(t, err, "primary: execution reverted")
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestFailoverClient_Exhausted(t *testing.T) {
+	unavailable := rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+	primary := &scriptedClient{errors: []error{unavailable, unavailable, unavailable}}
+	client := NewFailoverClient([]Endpoint{{Url: "primary", Client: primary}}, testRetryPolicy)
+
+	_, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	var endpointErr *EndpointError
+	assert.ErrorAs(t, err, &endpointErr)
+	assert.Equal(t, "primary", endpointErr.Url)
+	assert.Equal(t, 3, primary.calls)
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(rpc.HTTPError{StatusCode: 502}))
+	assert.False(t, IsRetryable(rpc.HTTPError{StatusCode: 400}))
+	assert.True(t, IsRetryable(context.DeadlineExceeded))
+	assert.False(t, IsRetryable(context.Canceled))
+	assert.True(t, IsRetryable(errors.New("daily request count exceeded, request rate limited")))
+	assert.False(t, IsRetryable(&RevertError{Data: []byte{0x01}}))
+	assert.False(t, IsRetryable(errors.New("invalid argument 0: hex string without 0x prefix")))
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrCircuitOpen = errors.New("circuit open for every endpoint")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+func (s CircuitState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// HealthOptions control when a circuit opens. Error rate and latency are
+// exponentially weighted moving averages with the weight Smoothing given to
+// the latest request. An open circuit is probed every ProbeInterval and closes
+// again after a successful probe.
+type HealthOptions struct {
+	Smoothing           float64
+	ErrorRateThreshold  float64
+	MinRequests         uint64
+	ConsecutiveFailures int
+	ProbeInterval       time.Duration
+	ProbeTimeout        time.Duration
+}
+
+var DefaultHealthOptions = HealthOptions{
+	Smoothing:           0.2,
+	ErrorRateThreshold:  0.5,
+	MinRequests:         5,
+	ConsecutiveFailures: 5,
+	ProbeInterval:       30 * time.Second,
+	ProbeTimeout:        5 * time.Second,
+}
+
+// EndpointHealth is a snapshot of the health of one endpoint.
+type EndpointHealth struct {
+	Url                 string        `json:"url"`
+	State               CircuitState  `json:"state"`
+	ErrorRate           float64       `json:"errorRate"`
+	Latency             time.Duration `json:"latency"`
+	Requests            uint64        `json:"requests"`
+	Failures            uint64        `json:"failures"`
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+	LastError           string        `json:"lastError,omitempty"`
+	OpenedAt            time.Time     `json:"openedAt,omitempty"`
+}
+
+// errorPenalty is added to the score of an endpoint in proportion to its error
+// rate, so an endpoint failing before it ever answered still ranks below a
+// healthy one.
+const errorPenalty = 10 * time.Second
+
+// Score orders endpoints, lower is healthier.
+func (h EndpointHealth) Score() float64 {
+	return float64(h.Latency)*(1+10*h.ErrorRate) + float64(errorPenalty)*h.ErrorRate
+}
+
+type endpointHealth struct {
+	EndpointHealth
+	client bind.ContractCaller
+	probe  *time.Timer
+}
+
+// HealthTracker keeps the health of endpoints by URL, so every FailoverClient
+// using the same tracker shares what it learns about an endpoint.
+type HealthTracker struct {
+	mu        sync.Mutex
+	options   HealthOptions
+	endpoints map[string]*endpointHealth
+}
+
+var DefaultHealth = NewHealthTracker(DefaultHealthOptions)
+
+func NewHealthTracker(options HealthOptions) *HealthTracker {
+	return &HealthTracker{options: options, endpoints: make(map[string]*endpointHealth)}
+}
+
+// Track registers client as the one probing url while its circuit is open.
+func (t *HealthTracker) Track(url string, client bind.ContractCaller) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endpoint(url).client = client
+}
+
+func (t *HealthTracker) endpoint(url string) *endpointHealth {
+	endpoint, ok := t.endpoints[url]
+	if !ok {
+		endpoint = &endpointHealth{EndpointHealth: EndpointHealth{Url: url}}
+		t.endpoints[url] = endpoint
+	}
+	return endpoint
+}
+
+// Allow reports whether traffic may be sent to url.
+func (t *HealthTracker) Allow(url string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.endpoint(url).State == CircuitClosed
+}
+
+// Record adds the outcome of a request to url. Only transient errors count as
+// failures, a revert still proves the endpoint is serving.
+func (t *HealthTracker) Record(url string, latency time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	endpoint := t.endpoint(url)
+	endpoint.Requests++
+	failed := IsRetryable(err)
+	sample := 0.0
+	if failed {
+		sample = 1
+		endpoint.Failures++
+		endpoint.ConsecutiveFailures++
+		endpoint.LastError = err.Error()
+	} else {
+		endpoint.ConsecutiveFailures = 0
+		endpoint.Latency = t.average(endpoint.Latency, latency)
+	}
+	endpoint.ErrorRate += t.options.Smoothing * (sample - endpoint.ErrorRate)
+
+	if endpoint.State != CircuitClosed || !failed {
+		return
+	}
+	if endpoint.ConsecutiveFailures >= t.options.ConsecutiveFailures ||
+		(endpoint.Requests >= t.options.MinRequests && endpoint.ErrorRate >= t.options.ErrorRateThreshold) {
+		t.open(endpoint)
+	}
+}
+
+func (t *HealthTracker) average(current, sample time.Duration) time.Duration {
+	if current == 0 {
+		return sample
+	}
+	return current + time.Duration(t.options.Smoothing*float64(sample-current))
+}
+
+// open must be called with the lock held.
+func (t *HealthTracker) open(endpoint *endpointHealth) {
+	endpoint.State = CircuitOpen
+	endpoint.OpenedAt = time.Now()
+	if endpoint.probe == nil && t.options.ProbeInterval > 0 {
+		url := endpoint.Url
+		endpoint.probe = time.AfterFunc(t.options.ProbeInterval, func() { t.Probe(url) })
+	}
+}
+
+// Probe checks an open endpoint with a cheap request and closes its circuit
+// when the endpoint answers. It is called periodically while a circuit is open.
+func (t *HealthTracker) Probe(url string) {
+	t.mu.Lock()
+	endpoint := t.endpoint(url)
+	endpoint.probe = nil
+	client := endpoint.client
+	if endpoint.State == CircuitClosed || client == nil {
+		t.mu.Unlock()
+		return
+	}
+	endpoint.State = CircuitHalfOpen
+	t.mu.Unlock()
+
+	ctx := context.Background()
+	if t.options.ProbeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.options.ProbeTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	_, err := client.CodeAt(ctx, common.Address{}, nil)
+	latency := time.Since(start)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		endpoint.LastError = err.Error()
+		t.open(endpoint)
+		return
+	}
+	endpoint.State = CircuitClosed
+	endpoint.OpenedAt = time.Time{}
+	endpoint.ErrorRate = 0
+	endpoint.ConsecutiveFailures = 0
+	endpoint.Latency = latency
+}
+
+// Order returns the endpoints with a closed circuit, healthiest first. Ties
+// keep the configured order.
+func (t *HealthTracker) Order(endpoints []Endpoint) []Endpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ordered := make([]Endpoint, 0, len(endpoints))
+	scores := make(map[string]float64, len(endpoints))
+	for _, endpoint := range endpoints {
+		health := t.endpoint(endpoint.Url)
+		if health.State == CircuitClosed {
+			ordered = append(ordered, endpoint)
+			scores[endpoint.Url] = health.Score()
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return scores[ordered[i].Url] < scores[ordered[j].Url]
+	})
+	return ordered
+}
+
+func (t *HealthTracker) Endpoint(url string) (EndpointHealth, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	endpoint, ok := t.endpoints[url]
+	if !ok {
+		return EndpointHealth{}, false
+	}
+	return endpoint.EndpointHealth, true
+}
+
+// Snapshot returns the health of every known endpoint sorted by URL.
+func (t *HealthTracker) Snapshot() []EndpointHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot := make([]EndpointHealth, 0, len(t.endpoints))
+	for _, endpoint := range t.endpoints {
+		snapshot = append(snapshot, endpoint.EndpointHealth)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Url < snapshot[j].Url
+	})
+	return snapshot
+}
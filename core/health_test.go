@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+var testHealthOptions = HealthOptions{
+	Smoothing:           0.5,
+	ErrorRateThreshold:  0.5,
+	MinRequests:         3,
+	ConsecutiveFailures: 3,
+	ProbeInterval:       20 * time.Millisecond,
+	ProbeTimeout:        time.Second,
+}
+
+func TestHealthTracker_OpenAndProbe(t *testing.T) {
+	health := NewHealthTracker(testHealthOptions)
+	health.Track("primary", &scriptedClient{})
+	unavailable := errors.New("service unavailable")
+
+	health.Record("primary", 10*time.Millisecond, nil)
+	health.Record("primary", 0, unavailable)
+	assert.True(t, health.Allow("primary"))
+	health.Record("primary", 0, unavailable)
+	assert.False(t, health.Allow("primary"))
+
+	snapshot := health.Snapshot()
+	assert.Len(t, snapshot, 1)
+	assert.Equal(t, CircuitOpen, snapshot[0].State)
+	assert.Equal(t, uint64(3), snapshot[0].Requests)
+	assert.Equal(t, uint64(2), snapshot[0].Failures)
+	assert.Equal(t, "service unavailable", snapshot[0].LastError)
+
+	assert.Eventually(t, func() bool { return health.Allow("primary") }, time.Second, 5*time.Millisecond)
+	endpoint, _ := health.Endpoint("primary")
+	assert.Equal(t, CircuitClosed, endpoint.State)
+	assert.Zero(t, endpoint.ErrorRate)
+}
+
+func TestHealthTracker_RevertIsHealthy(t *testing.T) {
+	health := NewHealthTracker(testHealthOptions)
+	for i := 0; i < 5; i++ {
+		health.Record("primary", time.Millisecond, errors.New("execution reverted"))
+	}
+	endpoint, _ := health.Endpoint("primary")
+	assert.Equal(t, CircuitClosed, endpoint.State)
+	assert.Zero(t, endpoint.Failures)
+}
+
+func TestHealthTracker_Order(t *testing.T) {
+	health := NewHealthTracker(testHealthOptions)
+	health.Record("slow", 50*time.Millisecond, nil)
+	health.Record("fast", 5*time.Millisecond, nil)
+	health.Record("down", time.Millisecond, nil)
+	for i := 0; i < 3; i++ {
+		health.Record("down", 0, errors.New("connection refused"))
+	}
+
+	ordered := health.Order([]Endpoint{{Url: "slow"}, {Url: "down"}, {Url: "fast"}})
+	assert.Equal(t, []Endpoint{{Url: "fast"}, {Url: "slow"}}, ordered)
+
+	// an endpoint that never answered ranks below one that did
+	health.Record("failing", 0, errors.New("connection refused"))
+	ordered = health.Order([]Endpoint{{Url: "failing"}, {Url: "slow"}})
+	assert.Equal(t, []Endpoint{{Url: "slow"}, {Url: "failing"}}, ordered)
+}
+
+func TestFailoverClient_HealthRetry(t *testing.T) {
+	health := NewHealthTracker(DefaultHealthOptions)
+	first := &scriptedClient{errors: []error{errors.New("service unavailable")}}
+	second := &scriptedClient{}
+	client := NewFailoverClient([]Endpoint{{Url: "first", Client: first}, {Url: "second", Client: second}}, testRetryPolicy).WithHealth(health)
+
+	_, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 1, second.calls)
+
+	// the failure ranks first behind second for the next call
+	_, err = client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 2, second.calls)
+}
+
+func TestFailoverClient_CircuitOpen(t *testing.T) {
+	health := NewHealthTracker(HealthOptions{Smoothing: 0.5, ErrorRateThreshold: 1, ConsecutiveFailures: 1})
+	unavailable := errors.New("service unavailable")
+	primary := &scriptedClient{errors: []error{unavailable}}
+	client := NewFailoverClient([]Endpoint{{Url: "primary", Client: primary}}, testRetryPolicy).WithHealth(health)
+
+	_, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	assert.ErrorIs(t, err, unavailable)
+	_, err = client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 1, primary.calls)
+}
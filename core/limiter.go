@@ -0,0 +1,321 @@
+package core
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type Priority int
+
+const (
+	PriorityBackground Priority = iota - 1
+	PriorityNormal
+	PriorityInteractive
+)
+
+type priorityKey struct{}
+
+// WithPriority marks the requests made with ctx. Waiting requests of a higher
+// priority are admitted first by every Limiter.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func PriorityFrom(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
+// Limits of a Limiter. A zero RequestsPerSecond or MaxConcurrent disables that
+// limit; Burst defaults to one request.
+type Limits struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty" yaml:"requestsPerSecond,omitempty"`
+	Burst             int     `json:"burst,omitempty" yaml:"burst,omitempty"`
+	MaxConcurrent     int     `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty"`
+}
+
+// Limiter is a token bucket combined with a concurrency limit whose waiters are
+// admitted by priority, then in arrival order.
+type Limiter struct {
+	mu       sync.Mutex
+	limits   Limits
+	tokens   float64
+	last     time.Time
+	inFlight int
+	waiters  waiterQueue
+	sequence uint64
+	timer    *time.Timer
+}
+
+type waiter struct {
+	priority Priority
+	sequence uint64
+	index    int
+	ready    chan struct{}
+}
+
+func NewLimiter(limits Limits) *Limiter {
+	limiter := &Limiter{last: time.Now()}
+	limiter.SetLimits(limits)
+	return limiter
+}
+
+func (l *Limiter) SetLimits(limits Limits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limits.Burst <= 0 {
+		limits.Burst = 1
+	}
+	l.limits = limits
+	l.tokens = math.Min(l.tokens, float64(limits.Burst))
+	if l.tokens == 0 && l.inFlight == 0 && len(l.waiters) == 0 {
+		l.tokens = float64(limits.Burst)
+	}
+	l.dispatch()
+}
+
+func (l *Limiter) Limits() Limits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limits
+}
+
+// Acquire waits until a request may start. The returned function must be
+// called once the request is done.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	l.sequence++
+	w := &waiter{priority: PriorityFrom(ctx), sequence: l.sequence, ready: make(chan struct{})}
+	heap.Push(&l.waiters, w)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.release, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-w.ready:
+			l.inFlight--
+			l.dispatch()
+		default:
+			heap.Remove(&l.waiters, w.index)
+			l.dispatch()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.dispatch()
+}
+
+// dispatch admits waiters in order while the limits allow it. It must be
+// called with the lock held.
+func (l *Limiter) dispatch() {
+	now := time.Now()
+	if l.limits.RequestsPerSecond > 0 {
+		l.tokens = math.Min(float64(l.limits.Burst), l.tokens+now.Sub(l.last).Seconds()*l.limits.RequestsPerSecond)
+	}
+	l.last = now
+	for len(l.waiters) > 0 {
+		if l.limits.MaxConcurrent > 0 && l.inFlight >= l.limits.MaxConcurrent {
+			return
+		}
+		if l.limits.RequestsPerSecond > 0 {
+			if l.tokens < 1 {
+				l.schedule(time.Duration((1 - l.tokens) / l.limits.RequestsPerSecond * float64(time.Second)))
+				return
+			}
+			l.tokens--
+		}
+		w := heap.Pop(&l.waiters).(*waiter)
+		l.inFlight++
+		close(w.ready)
+	}
+}
+
+func (l *Limiter) schedule(delay time.Duration) {
+	if l.timer != nil {
+		return
+	}
+	l.timer = time.AfterFunc(delay, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.timer = nil
+		l.dispatch()
+	})
+}
+
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].sequence < q[j].sequence
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return w
+}
+
+// Limiters holds one Limiter per endpoint URL plus an optional global
+// concurrency budget shared by all endpoints.
+type Limiters struct {
+	mu        sync.Mutex
+	endpoints map[string]*Limiter
+	global    *Limiter
+}
+
+var DefaultLimiters = NewLimiters()
+
+func NewLimiters() *Limiters {
+	return &Limiters{endpoints: make(map[string]*Limiter)}
+}
+
+// SetEndpointLimits configures the limits of url. Every client already limited
+// for url picks up the new limits.
+func (l *Limiters) SetEndpointLimits(url string, limits Limits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limiter, ok := l.endpoints[url]; ok {
+		limiter.SetLimits(limits)
+		return
+	}
+	l.endpoints[url] = NewLimiter(limits)
+}
+
+// SetGlobalConcurrency bounds the requests in flight across all endpoints, zero
+// removes the bound.
+func (l *Limiters) SetGlobalConcurrency(maxConcurrent int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.global == nil {
+		l.global = NewLimiter(Limits{MaxConcurrent: maxConcurrent})
+		return
+	}
+	l.global.SetLimits(Limits{MaxConcurrent: maxConcurrent})
+}
+
+// Client wraps client so its requests count against the limits of url and the
+// global budget. Limits set later for url apply to the returned client as well.
+func (l *Limiters) Client(url string, client bind.ContractCaller) bind.ContractCaller {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	endpoint, ok := l.endpoints[url]
+	if !ok {
+		endpoint = NewLimiter(Limits{})
+		l.endpoints[url] = endpoint
+	}
+	if l.global == nil {
+		l.global = NewLimiter(Limits{})
+	}
+	return &LimitedClient{client: client, limiters: []*Limiter{endpoint, l.global}}
+}
+
+var _ BlockCaller = (*LimitedClient)(nil)
+
+type LimitedClient struct {
+	client   bind.ContractCaller
+	limiters []*Limiter
+}
+
+func NewLimitedClient(client bind.ContractCaller, limiters ...*Limiter) *LimitedClient {
+	return &LimitedClient{client: client, limiters: limiters}
+}
+
+func (c *LimitedClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.client.CallContract(ctx, call, blockNumber)
+}
+
+func (c *LimitedClient) CallContractAtBlock(ctx context.Context, call ethereum.CallMsg, block BlockRef) ([]byte, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return CallAtBlock(ctx, c.client, call, block)
+}
+
+func (c *LimitedClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.client.CodeAt(ctx, contract, blockNumber)
+}
+
+func (c *LimitedClient) BlockHashAt(ctx context.Context, number *big.Int) (common.Hash, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer release()
+	return BlockHashAt(ctx, c.client, number)
+}
+
+func (c *LimitedClient) BlockHeaderAt(ctx context.Context, block BlockRef) (BlockHeader, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return BlockHeader{}, err
+	}
+	defer release()
+	return BlockHeaderAt(ctx, c.client, block)
+}
+
+func (c *LimitedClient) acquire(ctx context.Context) (func(), error) {
+	releases := make([]func(), 0, len(c.limiters))
+	releaseAll := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	for _, limiter := range c.limiters {
+		release, err := limiter.Acquire(ctx)
+		if err != nil {
+			releaseAll()
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+	return releaseAll, nil
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterAdmitsByPriority(t *testing.T) {
+	limiter := NewLimiter(Limits{MaxConcurrent: 1})
+	release, err := limiter.Acquire(context.Background())
+	assert.Nil(t, err)
+
+	order := make(chan Priority, 3)
+	var wg sync.WaitGroup
+	for _, priority := range []Priority{PriorityBackground, PriorityNormal, PriorityInteractive} {
+		wg.Add(1)
+		go func(priority Priority) {
+			defer wg.Done()
+			release, err := limiter.Acquire(WithPriority(context.Background(), priority))
+			assert.Nil(t, err)
+			order <- priority
+			release()
+		}(priority)
+		time.Sleep(10 * time.Millisecond)
+	}
+	release()
+	wg.Wait()
+	close(order)
+
+	var admitted []Priority
+	for priority := range order {
+		admitted = append(admitted, priority)
+	}
+	assert.Equal(t, []Priority{PriorityInteractive, PriorityNormal, PriorityBackground}, admitted)
+}
+
+func TestLimiterRate(t *testing.T) {
+	limiter := NewLimiter(Limits{RequestsPerSecond: 50})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := limiter.Acquire(context.Background())
+		assert.Nil(t, err)
+		release()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 55*time.Millisecond)
+}
+
+func TestLimiterCancel(t *testing.T) {
+	limiter := NewLimiter(Limits{MaxConcurrent: 1})
+	release, err := limiter.Acquire(context.Background())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release, err = limiter.Acquire(context.Background())
+	assert.Nil(t, err)
+	release()
+}
+
+type countingClient struct {
+	scriptedClient
+	inFlight int32
+	peak     int32
+}
+
+func (c *countingClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	current := atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&c.peak)
+		if current <= peak || atomic.CompareAndSwapInt32(&c.peak, peak, current) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return nil, nil
+}
+
+func TestLimitersShareEndpoint(t *testing.T) {
+	limiters := NewLimiters()
+	limiters.SetEndpointLimits("http://a", Limits{MaxConcurrent: 2})
+	client := &countingClient{}
+	first := limiters.Client("http://a", client)
+	second := limiters.Client("http://a", client)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(caller int) {
+			defer wg.Done()
+			if caller%2 == 0 {
+				_, _ = first.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+			} else {
+				_, _ = second.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), client.peak)
+
+	client = &countingClient{}
+	limiters.SetEndpointLimits("http://a", Limits{})
+	limiters.SetGlobalConcurrency(1)
+	first = limiters.Client("http://a", client)
+	other := limiters.Client("http://b", client)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(caller int) {
+			defer wg.Done()
+			if caller%2 == 0 {
+				_, _ = first.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+			} else {
+				_, _ = other.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), client.peak)
+}
@@ -2,12 +2,14 @@ package core
 
 import (
 	"context"
+	"errors"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"math/big"
 	"strings"
+	"time"
 )
 
 type Call struct {
@@ -27,13 +29,26 @@ func (call Call) GetMultiCall() MultiCall {
 	return MultiCall{Target: call.Target, CallData: call.CallData}
 }
 
+// MultiCaller executes calls through the multicall contract. With a Cache,
+// sub-calls at a block number or hash are looked up first and only the misses are sent;
+// reads of the latest block are cached for LatestTTL, or not at all when it is
+// zero. CacheNamespace, usually the chain, separates entries of several chains.
+// Identical sub-calls of a batch are sent once, and with Flights concurrent
+// batches share the sub-calls one of them already sent. AggregateOnly marks a
+// Multicall v1 contract, which lacks tryAggregate: calls that may fail run
+// through aggregate, and one by one when a sub-call reverts.
 type MultiCaller struct {
-	Client          *ethclient.Client
+	Client          bind.ContractCaller
 	Abi             abi.ABI
 	ContractAddress common.Address
+	Cache           Cache
+	CacheNamespace  string
+	LatestTTL       time.Duration
+	Flights         *FlightGroup
+	AggregateOnly   bool
 }
 
-func NewMultiCaller(client *ethclient.Client, contractAddress common.Address) (*MultiCaller, error) {
+func NewMultiCaller(client bind.ContractCaller, contractAddress common.Address) (*MultiCaller, error) {
 	mcAbi, err := abi.JSON(strings.NewReader(MultiMetaData.ABI))
 	if err != nil {
 		return nil, err
@@ -47,6 +62,53 @@ func NewMultiCaller(client *ethclient.Client, contractAddress common.Address) (*
 }
 
 func (caller *MultiCaller) StrictlyExecute(calls []Call, blockNumber *big.Int) (*big.Int, map[string]CallResponse, error) {
+	return caller.StrictlyExecuteAt(context.Background(), calls, BlockNumber(blockNumber))
+}
+
+func (caller *MultiCaller) StrictlyExecuteAt(ctx context.Context, calls []Call, block BlockRef) (*big.Int, map[string]CallResponse, error) {
+	// aggregate reports the block number, which the cache only knows for reads
+	// at a block number or at a hash read before
+	blockNumber, known := caller.cachedNumber(block)
+	results, misses := caller.cached(calls, true, block, known)
+	if caller.cacheable(block) && known && len(misses) == 0 {
+		return blockNumber, results, nil
+	}
+	unique, duplicates := dedupe(misses)
+	executedAt, executed, err := caller.aggregate(ctx, unique, block)
+	if err != nil {
+		return nil, nil, err
+	}
+	if block.Immutable() {
+		caller.store(unique, executed, block)
+		caller.store([]Call{blockNumberCall}, map[string]CallResponse{
+			blockNumberCall.Key: {Status: true, ReturnData: executedAt.Bytes()},
+		}, block)
+	}
+	fanOut(results, unique, duplicates, executed)
+	return executedAt, results, nil
+}
+
+// blockNumberCall keys the cached number of a block read by hash. No call to
+// the zero address with this data is ever sent.
+var blockNumberCall = Call{Key: "blockNumber", CallData: []byte("blockNumber")}
+
+func (caller *MultiCaller) cachedNumber(block BlockRef) (*big.Int, bool) {
+	if number, ok := block.Number(); ok {
+		return number, true
+	}
+	if !block.Immutable() || !caller.cacheable(block) {
+		return nil, false
+	}
+	response, ok := caller.Cache.Get(CacheKey(caller.CacheNamespace, block, blockNumberCall))
+	if !ok {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(response.ReturnData), true
+}
+
+// aggregate executes calls through aggregate, which reverts when any of them
+// does, and returns the block number they executed at.
+func (caller *MultiCaller) aggregate(ctx context.Context, calls []Call, block BlockRef) (*big.Int, map[string]CallResponse, error) {
 	var multiCalls = make([]MultiCall, 0, len(calls))
 	for _, call := range calls {
 		multiCalls = append(multiCalls, call.GetMultiCall())
@@ -55,7 +117,7 @@ func (caller *MultiCaller) StrictlyExecute(calls []Call, blockNumber *big.Int) (
 	if err != nil {
 		return nil, nil, err
 	}
-	resp, err := caller.Client.CallContract(context.Background(), ethereum.CallMsg{To: &caller.ContractAddress, Data: callData}, blockNumber)
+	resp, err := CallAtBlock(ctx, caller.Client, ethereum.CallMsg{To: &caller.ContractAddress, Data: callData}, block)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -66,7 +128,7 @@ func (caller *MultiCaller) StrictlyExecute(calls []Call, blockNumber *big.Int) (
 		return nil, nil, err
 	}
 
-	results := make(map[string]CallResponse)
+	results := make(map[string]CallResponse, len(calls))
 	for i, response := range responses[1].([][]byte) {
 		results[calls[i].Key] = CallResponse{
 			Method:     calls[i].Method,
@@ -77,7 +139,52 @@ func (caller *MultiCaller) StrictlyExecute(calls []Call, blockNumber *big.Int) (
 	return responses[0].(*big.Int), results, nil
 }
 
+// callEach executes calls one by one, outside the multicall contract, so a
+// reverting call only fails itself. Other errors fail every call.
+func (caller *MultiCaller) callEach(ctx context.Context, calls []Call, block BlockRef) (map[string]CallResponse, error) {
+	results := make(map[string]CallResponse, len(calls))
+	for _, call := range calls {
+		target := call.Target
+		data, err := CallAtBlock(ctx, caller.Client, ethereum.CallMsg{To: &target, Data: call.CallData}, block)
+		if err != nil && (ctx.Err() != nil || IsRetryable(err)) {
+			return nil, err
+		}
+		results[call.Key] = CallResponse{Method: call.Method, Status: err == nil, ReturnData: data}
+	}
+	return results, nil
+}
+
 func (caller *MultiCaller) Execute(ctx context.Context, calls []Call, requireSuccess bool) (map[string]CallResponse, error) {
+	return caller.ExecuteAt(ctx, calls, requireSuccess, nil)
+}
+
+func (caller *MultiCaller) ExecuteAt(ctx context.Context, calls []Call, requireSuccess bool, blockNumber *big.Int) (map[string]CallResponse, error) {
+	return caller.ExecuteAtBlock(ctx, calls, requireSuccess, BlockNumber(blockNumber))
+}
+
+func (caller *MultiCaller) ExecuteAtBlock(ctx context.Context, calls []Call, requireSuccess bool, block BlockRef) (map[string]CallResponse, error) {
+	results, misses := caller.cached(calls, requireSuccess, block, true)
+	if caller.cacheable(block) && len(misses) == 0 {
+		return results, nil
+	}
+	unique, duplicates := dedupe(misses)
+	responses, err := caller.shared(ctx, unique, requireSuccess, block)
+	if err != nil {
+		return nil, err
+	}
+	caller.store(unique, responses, block)
+	fanOut(results, unique, duplicates, responses)
+	return results, nil
+}
+
+func (caller *MultiCaller) tryAggregate(ctx context.Context, calls []Call, requireSuccess bool, block BlockRef) (map[string]CallResponse, error) {
+	if caller.AggregateOnly {
+		_, results, err := caller.aggregate(ctx, calls, block)
+		if err == nil || requireSuccess || ctx.Err() != nil || IsRetryable(err) {
+			return results, err
+		}
+		return caller.callEach(ctx, calls, block)
+	}
 	var multiCalls = make([]MultiCall, 0, len(calls))
 	for _, call := range calls {
 		multiCalls = append(multiCalls, call.GetMultiCall())
@@ -86,7 +193,7 @@ func (caller *MultiCaller) Execute(ctx context.Context, calls []Call, requireSuc
 	if err != nil {
 		return nil, err
 	}
-	resp, err := caller.Client.CallContract(ctx, ethereum.CallMsg{To: &caller.ContractAddress, Data: callData}, nil)
+	resp, err := CallAtBlock(ctx, caller.Client, ethereum.CallMsg{To: &caller.ContractAddress, Data: callData}, block)
 	if err != nil {
 		return nil, err
 	}
@@ -110,3 +217,118 @@ func (caller *MultiCaller) Execute(ctx context.Context, calls []Call, requireSuc
 	}
 	return results, nil
 }
+
+// cacheable reports whether reads at block use the cache: reads at a block
+// number or hash always do, latest reads with a LatestTTL, other tags never.
+func (caller *MultiCaller) cacheable(block BlockRef) bool {
+	if caller.Cache == nil {
+		return false
+	}
+	return block.Immutable() || (block.IsLatest() && caller.LatestTTL > 0)
+}
+
+// cached splits calls into the responses found in the cache and the calls that
+// still have to be executed. Cached failures are misses when success is required,
+// so the multicall fails as it would without the cache.
+func (caller *MultiCaller) cached(calls []Call, requireSuccess bool, block BlockRef, lookup bool) (map[string]CallResponse, []Call) {
+	results := make(map[string]CallResponse, len(calls))
+	if !lookup || !caller.cacheable(block) {
+		return results, calls
+	}
+	misses := make([]Call, 0, len(calls))
+	for _, call := range calls {
+		response, ok := caller.Cache.Get(CacheKey(caller.CacheNamespace, block, call))
+		if !ok || (requireSuccess && !response.Status) {
+			misses = append(misses, call)
+			continue
+		}
+		response.Method = call.Method
+		results[call.Key] = response
+	}
+	return results, misses
+}
+
+func (caller *MultiCaller) store(calls []Call, results map[string]CallResponse, block BlockRef) {
+	if !caller.cacheable(block) {
+		return
+	}
+	var ttl time.Duration
+	if !block.Immutable() {
+		ttl = caller.LatestTTL
+	}
+	for _, call := range calls {
+		caller.Cache.Set(CacheKey(caller.CacheNamespace, block, call), results[call.Key], ttl)
+	}
+}
+
+var ErrTryBlockUnsupported = errors.New("multicall v1 cannot report the block hash")
+
+// Block is the block a multicall executed at. Nodes run eth_call in the
+// context of the requested block, where the BLOCKHASH of that block itself is
+// not available yet, so ExecuteBlock reads the hash from the block header.
+type Block struct {
+	Number *big.Int    `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// ExecuteBlock is ExecuteAt through tryBlockAndAggregate, which also reports the
+// block the calls executed at. It needs Multicall2 or later. Unless block is a
+// hash, the header of block is read first when the client is a HeaderReader and
+// the calls run at its hash with requireCanonical, so the reported hash is the
+// one of the state read even across a reorg or another endpoint. Otherwise the
+// hash is the one reported by the multicall, usually zero.
+func (caller *MultiCaller) ExecuteBlock(ctx context.Context, calls []Call, requireSuccess bool, block BlockRef) (Block, map[string]CallResponse, error) {
+	if caller.AggregateOnly {
+		return Block{}, nil, ErrTryBlockUnsupported
+	}
+	hash, pinned := block.Hash()
+	if !pinned {
+		header, err := BlockHeaderAt(ctx, caller.Client, block)
+		switch {
+		case errors.Is(err, ErrBlockHeaderUnsupported):
+		case err != nil:
+			return Block{}, nil, err
+		case header.Hash != (common.Hash{}):
+			hash, pinned = header.Hash, true
+			block = BlockHash(header.Hash, true)
+		}
+	}
+
+	unique, duplicates := dedupe(calls)
+	var multiCalls = make([]MultiCall, 0, len(unique))
+	for _, call := range unique {
+		multiCalls = append(multiCalls, call.GetMultiCall())
+	}
+	callData, err := caller.Abi.Pack("tryBlockAndAggregate", requireSuccess, multiCalls)
+	if err != nil {
+		return Block{}, nil, err
+	}
+	resp, err := CallAtBlock(ctx, caller.Client, ethereum.CallMsg{To: &caller.ContractAddress, Data: callData}, block)
+	if err != nil {
+		return Block{}, nil, err
+	}
+
+	responses, err := caller.Abi.Unpack("tryBlockAndAggregate", resp)
+	if err != nil {
+		return Block{}, nil, err
+	}
+
+	executedAt := Block{Number: responses[0].(*big.Int), Hash: responses[1].([32]byte)}
+	if pinned {
+		executedAt.Hash = hash
+	}
+	executed := make(map[string]CallResponse, len(unique))
+	for i, response := range responses[2].([]struct {
+		Success    bool   `json:"success"`
+		ReturnData []byte `json:"returnData"`
+	}) {
+		executed[unique[i].Key] = CallResponse{
+			Method:     unique[i].Method,
+			ReturnData: response.ReturnData,
+			Status:     response.Success,
+		}
+	}
+	results := make(map[string]CallResponse, len(calls))
+	fanOut(results, unique, duplicates, executed)
+	return executedAt, results, nil
+}
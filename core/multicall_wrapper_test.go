@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// v1Client is a Multicall v1 contract at testMultiCallAddress, which only has
+// aggregate, and echoes the data of direct calls to other contracts, except
+// those to reverting.
+type v1Client struct {
+	aggregateClient
+	reverting common.Address
+	direct    int
+}
+
+func (c *v1Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	reverted := errors.New("execution reverted")
+	if *call.To != testMultiCallAddress {
+		c.direct++
+		if *call.To == c.reverting {
+			return nil, reverted
+		}
+		return call.Data, nil
+	}
+	mcAbi, err := abi.JSON(strings.NewReader(MultiMetaData.ABI))
+	if err != nil {
+		return nil, err
+	}
+	method, err := mcAbi.MethodById(call.Data)
+	if err != nil || method.Name != "aggregate" {
+		return nil, reverted
+	}
+	inputs, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range inputs[0].([]struct {
+		Target   common.Address `json:"target"`
+		CallData []byte         `json:"callData"`
+	}) {
+		if sub.Target == c.reverting {
+			return nil, reverted
+		}
+	}
+	return c.aggregateClient.CallContract(ctx, call, blockNumber)
+}
+
+func TestMultiCaller_AggregateOnly(t *testing.T) {
+	client := &v1Client{reverting: common.HexToAddress("0x02")}
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+	caller.AggregateOnly = true
+
+	calls := []Call{
+		{Key: "ok", Method: "a", Target: common.HexToAddress("0x01"), CallData: []byte{1}},
+		{Key: "other", Method: "b", Target: common.HexToAddress("0x03"), CallData: []byte{3}},
+	}
+	results, err := caller.Execute(context.Background(), calls, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{3}, results["other"].ReturnData)
+	assert.Zero(t, client.direct)
+
+	// a reverting sub-call fails aggregate, so the calls run one by one
+	calls = append(calls, Call{Key: "reverting", Method: "c", Target: client.reverting, CallData: []byte{2}})
+	results, err = caller.Execute(context.Background(), calls, false)
+	assert.NoError(t, err)
+	assert.True(t, results["ok"].Status)
+	assert.Equal(t, []byte{1}, results["ok"].ReturnData)
+	assert.False(t, results["reverting"].Status)
+	assert.Equal(t, 3, client.direct)
+
+	_, err = caller.Execute(context.Background(), calls, true)
+	assert.EqualError(t, err, "execution reverted")
+
+	_, _, err = caller.ExecuteBlock(context.Background(), calls, false, LatestBlock)
+	assert.ErrorIs(t, err, ErrTryBlockUnsupported)
+}
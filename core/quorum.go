@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EndpointResult is the answer of one endpoint to a hedged or quorum read.
+type EndpointResult struct {
+	Url     string
+	Block   Block
+	Results map[string]CallResponse
+	Err     error
+}
+
+// QuorumResult is the agreed answer of a read. Diverging lists the endpoints
+// that answered differently, Failed those that returned an error.
+type QuorumResult struct {
+	Block     Block
+	Results   map[string]CallResponse
+	Agreeing  []string
+	Diverging []EndpointResult
+	Failed    []EndpointResult
+}
+
+func (r *QuorumResult) Diverged() bool {
+	return len(r.Diverging) > 0
+}
+
+type QuorumError struct {
+	Quorum    int
+	Responses []EndpointResult
+}
+
+func (e *QuorumError) Error() string {
+	answers := make([]string, 0, len(e.Responses))
+	for _, response := range e.Responses {
+		if response.Err != nil {
+			answers = append(answers, fmt.Sprintf("%s: %v", response.Url, response.Err))
+		} else {
+			answers = append(answers, fmt.Sprintf("%s: block %s %s", response.Url, response.Block.Number, response.Block.Hash.Hex()))
+		}
+	}
+	return fmt.Sprintf("quorum of %d not reached (%s)", e.Quorum, strings.Join(answers, "; "))
+}
+
+// QuorumCaller sends the same multicall to several endpoints, either taking the
+// first success or requiring matching answers. Every endpoint executes at the
+// same block number and answers are compared with the block hash they report.
+type QuorumCaller struct {
+	endpoints []Endpoint
+	callers   []*MultiCaller
+}
+
+func NewQuorumCaller(endpoints []Endpoint, contractAddress common.Address) (*QuorumCaller, error) {
+	callers := make([]*MultiCaller, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		caller, err := NewMultiCaller(endpoint.Client, contractAddress)
+		if err != nil {
+			return nil, err
+		}
+		callers = append(callers, caller)
+	}
+	return &QuorumCaller{endpoints: endpoints, callers: callers}, nil
+}
+
+// Hedged sends the calls to the first endpoint, then to the next one every
+// delay without a success, or to all of them at once with a zero delay. The
+// first success is returned and the other requests are cancelled.
+func (q *QuorumCaller) Hedged(ctx context.Context, calls []Call, requireSuccess bool, block BlockRef, delay time.Duration) (*QuorumResult, error) {
+	if len(q.endpoints) == 0 {
+		return nil, errors.New("no endpoints configured")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	responses := make(chan EndpointResult, len(q.endpoints))
+	launched := 0
+	launch := func() {
+		go q.execute(ctx, launched, calls, requireSuccess, block, responses)
+		launched++
+	}
+
+	launch()
+	for delay <= 0 && launched < len(q.endpoints) {
+		launch()
+	}
+	var hedge <-chan time.Time
+	if launched < len(q.endpoints) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+
+	var failed []EndpointResult
+	for {
+		select {
+		case response := <-responses:
+			if response.Err == nil {
+				return &QuorumResult{
+					Block:    response.Block,
+					Results:  response.Results,
+					Agreeing: []string{response.Url},
+					Failed:   failed,
+				}, nil
+			}
+			failed = append(failed, response)
+			if len(failed) == len(q.endpoints) {
+				return nil, &QuorumError{Quorum: 1, Responses: failed}
+			}
+			if len(failed) == launched {
+				launch()
+			}
+		case <-hedge:
+			if launched < len(q.endpoints) {
+				launch()
+			}
+			if launched < len(q.endpoints) {
+				hedge = time.After(delay)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Quorum requires quorum endpoints to report the same block and identical
+// results. A read at a block tag is pinned to the block hash of the first
+// answer: answers at that block are kept and the other endpoints are asked
+// again at the pinned hash, so an endpoint on another fork fails instead of
+// reading its own block at that number. Without a hash the read is pinned to
+// the block number.
+func (q *QuorumCaller) Quorum(ctx context.Context, calls []Call, requireSuccess bool, block BlockRef, quorum int) (*QuorumResult, error) {
+	if quorum < 1 {
+		quorum = 1
+	}
+	if quorum > len(q.endpoints) {
+		return nil, fmt.Errorf("quorum of %d needs more than %d endpoints", quorum, len(q.endpoints))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pinned := block.Immutable()
+	var pin Block
+	tagged := make(chan taggedResult, len(q.endpoints))
+	responses := make(chan EndpointResult, len(q.endpoints))
+	for i := range q.endpoints {
+		if pinned {
+			go q.execute(ctx, i, calls, requireSuccess, block, responses)
+			continue
+		}
+		go func(i int, block BlockRef) {
+			tagged <- taggedResult{endpoint: i, EndpointResult: q.read(ctx, i, calls, requireSuccess, block)}
+		}(i, block)
+	}
+
+	groups := make(map[string][]EndpointResult)
+	var all []EndpointResult
+	for len(all) < len(q.endpoints) {
+		var response EndpointResult
+		select {
+		case response = <-responses:
+		case answer := <-tagged:
+			response = answer.EndpointResult
+			if response.Err == nil && !pinned {
+				pinned, pin = true, response.Block
+				block = BlockNumber(pin.Number)
+				if pin.Hash != (common.Hash{}) {
+					block = BlockHash(pin.Hash, true)
+				}
+			} else if response.Err == nil && !sameBlock(response.Block, pin) {
+				go q.execute(ctx, answer.endpoint, calls, requireSuccess, block, responses)
+				continue
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		all = append(all, response)
+		if response.Err != nil {
+			continue
+		}
+		key := fingerprint(calls, response)
+		groups[key] = append(groups[key], response)
+		if len(groups[key]) < quorum {
+			continue
+		}
+		result := &QuorumResult{Block: response.Block, Results: response.Results}
+		for _, other := range all {
+			switch {
+			case other.Err != nil:
+				result.Failed = append(result.Failed, other)
+			case fingerprint(calls, other) == key:
+				result.Agreeing = append(result.Agreeing, other.Url)
+			default:
+				result.Diverging = append(result.Diverging, other)
+			}
+		}
+		return result, nil
+	}
+	return nil, &QuorumError{Quorum: quorum, Responses: all}
+}
+
+// taggedResult is the answer of an endpoint to a read at a block tag.
+type taggedResult struct {
+	endpoint int
+	EndpointResult
+}
+
+func (q *QuorumCaller) execute(ctx context.Context, i int, calls []Call, requireSuccess bool, block BlockRef, responses chan<- EndpointResult) {
+	responses <- q.read(ctx, i, calls, requireSuccess, block)
+}
+
+func (q *QuorumCaller) read(ctx context.Context, i int, calls []Call, requireSuccess bool, block BlockRef) EndpointResult {
+	executedAt, results, err := q.callers[i].ExecuteBlock(ctx, calls, requireSuccess, block)
+	return EndpointResult{Url: q.endpoints[i].Url, Block: executedAt, Results: results, Err: err}
+}
+
+// sameBlock reports whether an answer was read at the pinned block, by hash
+// when the pin has one.
+func sameBlock(block Block, pin Block) bool {
+	if pin.Hash != (common.Hash{}) {
+		return block.Hash == pin.Hash
+	}
+	return block.Number != nil && pin.Number != nil && block.Number.Cmp(pin.Number) == 0
+}
+
+func fingerprint(calls []Call, response EndpointResult) string {
+	var builder strings.Builder
+	builder.WriteString(response.Block.Number.String())
+	builder.WriteString(response.Block.Hash.Hex())
+	for _, call := range calls {
+		result := response.Results[call.Key]
+		fmt.Fprintf(&builder, ";%s:%t:%s", call.Key, result.Status, hex.EncodeToString(result.ReturnData))
+	}
+	return builder.String()
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockClient answers tryBlockAndAggregate with answer as the return data of
+// every call, at the requested block or at head for the latest block. With
+// canonical set, the multicall reports a zero hash as nodes do and block hashes
+// and headers are read from canonical. Otherwise the hash of a block is its
+// number.
+type blockClient struct {
+	head      int64
+	answer    byte
+	delay     time.Duration
+	err       error
+	canonical map[int64]common.Hash
+
+	mu     sync.Mutex
+	blocks []*big.Int
+}
+
+func (c *blockClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	c.blocks = append(c.blocks, blockNumber)
+	c.mu.Unlock()
+	if err := sleep(ctx, c.delay); err != nil {
+		return nil, err
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	mcAbi, err := abi.JSON(strings.NewReader(MultiMetaData.ABI))
+	if err != nil {
+		return nil, err
+	}
+	method := mcAbi.Methods["tryBlockAndAggregate"]
+	inputs, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := inputs[1].([]struct {
+		Target   common.Address `json:"target"`
+		CallData []byte         `json:"callData"`
+	})
+	results := make([]struct {
+		Success    bool
+		ReturnData []byte
+	}, len(calls))
+	for i := range calls {
+		results[i].Success = true
+		results[i].ReturnData = []byte{c.answer}
+	}
+	if blockNumber == nil {
+		blockNumber = big.NewInt(c.head)
+	}
+	if c.canonical != nil {
+		return method.Outputs.Pack(blockNumber, common.Hash{}, results)
+	}
+	return method.Outputs.Pack(blockNumber, common.BigToHash(blockNumber), results)
+}
+
+func (c *blockClient) CallContractAtBlock(ctx context.Context, call ethereum.CallMsg, block BlockRef) ([]byte, error) {
+	hash, ok := block.Hash()
+	if !ok {
+		number, _ := block.Number()
+		return c.CallContract(ctx, call, number)
+	}
+	if c.canonical == nil {
+		return c.CallContract(ctx, call, hash.Big())
+	}
+	for number, canonical := range c.canonical {
+		if canonical == hash {
+			return c.CallContract(ctx, call, big.NewInt(number))
+		}
+	}
+	return nil, errors.New("unknown block")
+}
+
+func (c *blockClient) BlockHeaderAt(ctx context.Context, block BlockRef) (BlockHeader, error) {
+	if c.canonical == nil {
+		return BlockHeader{}, ErrBlockHeaderUnsupported
+	}
+	number, ok := block.Number()
+	if !ok {
+		number = big.NewInt(c.head)
+	}
+	return BlockHeader{Number: number, Hash: c.canonical[number.Int64()]}, nil
+}
+
+func (c *blockClient) BlockHashAt(ctx context.Context, number *big.Int) (common.Hash, error) {
+	if c.canonical == nil {
+		return common.Hash{}, ErrBlockHashUnsupported
+	}
+	return c.canonical[number.Int64()], nil
+}
+
+func (c *blockClient) requested() []*big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*big.Int(nil), c.blocks...)
+}
+
+func (c *blockClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+var quorumCalls = []Call{{Key: "price", Method: "latestAnswer", Target: common.BigToAddress(common.Big1)}}
+
+func newQuorumCaller(t *testing.T, clients map[string]*blockClient, urls ...string) *QuorumCaller {
+	endpoints := make([]Endpoint, 0, len(urls))
+	for _, url := range urls {
+		endpoints = append(endpoints, Endpoint{Url: url, Client: clients[url]})
+	}
+	caller, err := NewQuorumCaller(endpoints, testMultiCallAddress)
+	assert.NoError(t, err)
+	return caller
+}
+
+func TestQuorumCaller_Hedged(t *testing.T) {
+	clients := map[string]*blockClient{
+		"slow": {head: 100, answer: 1, delay: time.Second},
+		"fast": {head: 100, answer: 1},
+	}
+	caller := newQuorumCaller(t, clients, "slow", "fast")
+
+	start := time.Now()
+	result, err := caller.Hedged(context.Background(), quorumCalls, true, LatestBlock, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, []string{"fast"}, result.Agreeing)
+	assert.Equal(t, []byte{1}, result.Results["price"].ReturnData)
+
+	clients = map[string]*blockClient{
+		"down": {err: errors.New("connection refused")},
+		"up":   {head: 100, answer: 1},
+	}
+	caller = newQuorumCaller(t, clients, "down", "up")
+	result, err = caller.Hedged(context.Background(), quorumCalls, true, BlockNumber(big.NewInt(90)), time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"up"}, result.Agreeing)
+	assert.Equal(t, "down", result.Failed[0].Url)
+	assert.Equal(t, big.NewInt(90), result.Block.Number)
+}
+
+func TestQuorumCaller_Quorum(t *testing.T) {
+	clients := map[string]*blockClient{
+		"a":     {head: 100, answer: 1},
+		"b":     {head: 101, answer: 1, delay: 50 * time.Millisecond},
+		"lying": {head: 100, answer: 2, delay: 5 * time.Millisecond},
+	}
+	caller := newQuorumCaller(t, clients, "a", "b", "lying")
+
+	result, err := caller.Quorum(context.Background(), quorumCalls, true, LatestBlock, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), result.Block.Number)
+	assert.ElementsMatch(t, []string{"a", "b"}, result.Agreeing)
+	assert.True(t, result.Diverged())
+	assert.Equal(t, "lying", result.Diverging[0].Url)
+	assert.Contains(t, clients["b"].requested(), big.NewInt(100))
+
+	_, err = caller.Quorum(context.Background(), quorumCalls, true, BlockNumber(big.NewInt(100)), 3)
+	var quorumErr *QuorumError
+	assert.ErrorAs(t, err, &quorumErr)
+	assert.Len(t, quorumErr.Responses, 3)
+
+	_, err = caller.Quorum(context.Background(), quorumCalls, true, LatestBlock, 4)
+	assert.EqualError(t, err, "quorum of 4 needs more than 3 endpoints")
+}
+
+func TestQuorumCaller_QuorumPinsHash(t *testing.T) {
+	canonical := common.HexToHash("0xa1")
+	clients := map[string]*blockClient{
+		"a":    {head: 100, answer: 1, canonical: map[int64]common.Hash{100: canonical}},
+		"b":    {head: 100, answer: 1, delay: 20 * time.Millisecond, canonical: map[int64]common.Hash{100: canonical}},
+		"fork": {head: 100, answer: 1, delay: 5 * time.Millisecond, canonical: map[int64]common.Hash{100: common.HexToHash("0xf1")}},
+	}
+	caller := newQuorumCaller(t, clients, "a", "b", "fork")
+
+	result, err := caller.Quorum(context.Background(), quorumCalls, true, LatestBlock, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, Block{Number: big.NewInt(100), Hash: canonical}, result.Block)
+	assert.ElementsMatch(t, []string{"a", "b"}, result.Agreeing)
+	// the fork does not know the block of the first answer
+	assert.Len(t, result.Failed, 1)
+	assert.Equal(t, "fork", result.Failed[0].Url)
+	// answers at the pinned block are not read again
+	assert.Len(t, clients["a"].requested(), 1)
+	assert.Len(t, clients["b"].requested(), 1)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrBlockHashUnsupported = errors.New("client cannot read block hashes")
+
+// HashReader is a client that reads the hash of the canonical block at a number.
+type HashReader interface {
+	BlockHashAt(ctx context.Context, number *big.Int) (common.Hash, error)
+}
+
+// BlockHashAt reads the canonical hash at number through client.
+func BlockHashAt(ctx context.Context, client bind.ContractCaller, number *big.Int) (common.Hash, error) {
+	if reader, ok := client.(HashReader); ok {
+		return reader.BlockHashAt(ctx, number)
+	}
+	return common.Hash{}, ErrBlockHashUnsupported
+}
+
+// ReorgError reports a block that is no longer part of the canonical chain,
+// along with the hash of the block that replaced it.
+type ReorgError struct {
+	Block     Block
+	Canonical common.Hash
+}
+
+func (e *ReorgError) Error() string {
+	return fmt.Sprintf("block %s %s was reorged out, canonical hash is %s", e.Block.Number, e.Block.Hash.Hex(), e.Canonical.Hex())
+}
+
+// BlockVerifier re-checks blocks that results were read at against the
+// canonical chain, so results stored per block can be flagged once their block
+// was orphaned.
+type BlockVerifier struct {
+	client bind.ContractCaller
+}
+
+func NewBlockVerifier(client bind.ContractCaller) *BlockVerifier {
+	return &BlockVerifier{client: client}
+}
+
+// Verify returns a ReorgError when block is no longer canonical.
+func (v *BlockVerifier) Verify(ctx context.Context, block Block) error {
+	orphaned, err := v.Orphaned(ctx, []Block{block})
+	if err != nil {
+		return err
+	}
+	if len(orphaned) > 0 {
+		return orphaned[0]
+	}
+	return nil
+}
+
+// Orphaned verifies blocks and returns those no longer canonical. Blocks at
+// the same number are checked with a single request.
+func (v *BlockVerifier) Orphaned(ctx context.Context, blocks []Block) ([]*ReorgError, error) {
+	canonical := make(map[string]common.Hash)
+	var orphaned []*ReorgError
+	for _, block := range blocks {
+		if block.Number == nil || block.Hash == (common.Hash{}) {
+			return nil, errors.New("block has no number or hash to verify")
+		}
+		number := block.Number.String()
+		hash, ok := canonical[number]
+		if !ok {
+			var err error
+			hash, err = BlockHashAt(ctx, v.client, block.Number)
+			if err != nil {
+				return nil, err
+			}
+			canonical[number] = hash
+		}
+		if hash != block.Hash {
+			orphaned = append(orphaned, &ReorgError{Block: block, Canonical: hash})
+		}
+	}
+	return orphaned, nil
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiCaller_ExecuteBlockHash(t *testing.T) {
+	client := &blockClient{head: 100, answer: 1, canonical: map[int64]common.Hash{100: common.HexToHash("0xa1")}}
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+
+	executedAt, results, err := caller.ExecuteBlock(context.Background(), quorumCalls, true, LatestBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, Block{Number: big.NewInt(100), Hash: common.HexToHash("0xa1")}, executedAt)
+	assert.Equal(t, []byte{1}, results["price"].ReturnData)
+	// the calls ran at the hash of the header read first
+	assert.Equal(t, []*big.Int{big.NewInt(100)}, client.requested())
+
+	// the head moving on does not change the block reported for a pinned read
+	client.head, client.canonical[101] = 101, common.HexToHash("0xa2")
+	executedAt, _, err = caller.ExecuteBlock(context.Background(), quorumCalls, true, BlockHash(common.HexToHash("0xa1"), true))
+	assert.NoError(t, err)
+	assert.Equal(t, Block{Number: big.NewInt(100), Hash: common.HexToHash("0xa1")}, executedAt)
+}
+
+func TestBlockVerifier(t *testing.T) {
+	client := &blockClient{canonical: map[int64]common.Hash{
+		100: common.HexToHash("0xa1"),
+		101: common.HexToHash("0xa2"),
+	}}
+	verifier := NewBlockVerifier(client)
+
+	kept := Block{Number: big.NewInt(100), Hash: common.HexToHash("0xa1")}
+	orphan := Block{Number: big.NewInt(101), Hash: common.HexToHash("0xb2")}
+	assert.NoError(t, verifier.Verify(context.Background(), kept))
+
+	err := verifier.Verify(context.Background(), orphan)
+	var reorg *ReorgError
+	assert.ErrorAs(t, err, &reorg)
+	assert.Equal(t, common.HexToHash("0xa2"), reorg.Canonical)
+
+	orphaned, err := verifier.Orphaned(context.Background(), []Block{kept, orphan, kept})
+	assert.NoError(t, err)
+	assert.Len(t, orphaned, 1)
+	assert.Equal(t, orphan, orphaned[0].Block)
+
+	_, err = verifier.Orphaned(context.Background(), []Block{{Number: big.NewInt(100)}})
+	assert.Error(t, err)
+	_, err = NewBlockVerifier(&aggregateClient{}).Orphaned(context.Background(), []Block{kept})
+	assert.ErrorIs(t, err, ErrBlockHashUnsupported)
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrBlockHeaderUnsupported = errors.New("client cannot read block headers")
+
+// BlockHeader is the number, hash and timestamp of a block.
+type BlockHeader struct {
+	Number *big.Int    `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	Time   uint64      `json:"time"`
+}
+
+// HeaderReader is a client that reads the header of a block by tag, number or
+// hash.
+type HeaderReader interface {
+	BlockHeaderAt(ctx context.Context, block BlockRef) (BlockHeader, error)
+}
+
+// BlockHeaderAt reads the header of block through client.
+func BlockHeaderAt(ctx context.Context, client bind.ContractCaller, block BlockRef) (BlockHeader, error) {
+	if reader, ok := client.(HeaderReader); ok {
+		return reader.BlockHeaderAt(ctx, block)
+	}
+	return BlockHeader{}, ErrBlockHeaderUnsupported
+}
+
+// BlockRange returns every step-th block from from up to and including to.
+func BlockRange(from, to, step uint64) []*big.Int {
+	if step == 0 {
+		step = 1
+	}
+	var blocks []*big.Int
+	for number := from; number <= to; number += step {
+		blocks = append(blocks, new(big.Int).SetUint64(number))
+		if number+step < number {
+			break
+		}
+	}
+	return blocks
+}
+
+// DailyTimes returns a time every day from from up to and including to, at the
+// time of day of from.
+func DailyTimes(from, to time.Time) []time.Time {
+	var times []time.Time
+	for at := from; !at.After(to); at = at.AddDate(0, 0, 1) {
+		times = append(times, at)
+	}
+	return times
+}
+
+// BlockFinder finds the last block mined at or before a time. Blocks it read
+// are kept and narrow the search of later times.
+type BlockFinder struct {
+	client bind.ContractCaller
+
+	mu      sync.Mutex
+	headers map[uint64]BlockHeader
+	latest  *BlockHeader
+}
+
+func NewBlockFinder(client bind.ContractCaller) *BlockFinder {
+	return &BlockFinder{client: client, headers: make(map[uint64]BlockHeader)}
+}
+
+// BlockAt searches the blocks between the closest ones already read, guessing
+// from the block time and bisecting every other step so irregular block times
+// still take a logarithmic number of reads.
+func (f *BlockFinder) BlockAt(ctx context.Context, at time.Time) (BlockHeader, error) {
+	target := uint64(at.Unix())
+	latest, err := f.latestHeader(ctx, target)
+	if err != nil {
+		return BlockHeader{}, err
+	}
+	if latest.Time < target {
+		return BlockHeader{}, fmt.Errorf("%s is after the latest block", at.UTC().Format(time.RFC3339))
+	}
+	if latest.Time == target {
+		return latest, nil
+	}
+	lo, hi, err := f.bounds(ctx, target, latest)
+	if err != nil {
+		return BlockHeader{}, err
+	}
+	if lo.Time > target {
+		return BlockHeader{}, fmt.Errorf("%s is before the first block", at.UTC().Format(time.RFC3339))
+	}
+	for bisect := false; hi.Number.Uint64()-lo.Number.Uint64() > 1; bisect = !bisect {
+		low, high := lo.Number.Uint64(), hi.Number.Uint64()
+		guess := low + (high-low)/2
+		if !bisect && hi.Time > lo.Time {
+			guess = low + uint64(float64(high-low)*float64(target-lo.Time)/float64(hi.Time-lo.Time))
+		}
+		if guess <= low {
+			guess = low + 1
+		}
+		if guess >= high {
+			guess = high - 1
+		}
+		header, err := f.header(ctx, guess)
+		if err != nil {
+			return BlockHeader{}, err
+		}
+		if header.Time <= target {
+			lo = header
+		} else {
+			hi = header
+		}
+	}
+	return lo, nil
+}
+
+// latestHeader returns the latest block read, reading it again when it is
+// older than target.
+func (f *BlockFinder) latestHeader(ctx context.Context, target uint64) (BlockHeader, error) {
+	f.mu.Lock()
+	latest := f.latest
+	f.mu.Unlock()
+	if latest != nil && latest.Time >= target {
+		return *latest, nil
+	}
+	header, err := BlockHeaderAt(ctx, f.client, LatestBlock)
+	if err != nil {
+		return BlockHeader{}, err
+	}
+	f.mu.Lock()
+	f.latest = &header
+	f.headers[header.Number.Uint64()] = header
+	f.mu.Unlock()
+	return header, nil
+}
+
+// bounds returns the closest blocks read so far around target, reading the
+// first block when none is older.
+func (f *BlockFinder) bounds(ctx context.Context, target uint64, latest BlockHeader) (BlockHeader, BlockHeader, error) {
+	f.mu.Lock()
+	numbers := make([]uint64, 0, len(f.headers))
+	for number := range f.headers {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	var lo *BlockHeader
+	hi := latest
+	for _, number := range numbers {
+		header := f.headers[number]
+		if header.Time <= target {
+			lo = &header
+		} else {
+			hi = header
+			break
+		}
+	}
+	f.mu.Unlock()
+	if lo != nil {
+		return *lo, hi, nil
+	}
+	first, err := f.header(ctx, 0)
+	return first, hi, err
+}
+
+func (f *BlockFinder) header(ctx context.Context, number uint64) (BlockHeader, error) {
+	f.mu.Lock()
+	header, ok := f.headers[number]
+	f.mu.Unlock()
+	if ok {
+		return header, nil
+	}
+	header, err := BlockHeaderAt(ctx, f.client, BlockNumber(new(big.Int).SetUint64(number)))
+	if err != nil {
+		return BlockHeader{}, err
+	}
+	f.mu.Lock()
+	f.headers[number] = header
+	f.mu.Unlock()
+	return header, nil
+}
+
+// Sample executes the same calls at every block, at most concurrency blocks at
+// a time, and returns the results in the order of blocks. Reads go through the
+// cache, so a sampling that failed part way only sends the missing blocks when
+// run again. The first error cancels the blocks still running.
+func (caller *MultiCaller) Sample(ctx context.Context, calls []Call, requireSuccess bool, blocks []*big.Int, concurrency int) ([]map[string]CallResponse, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	samples := make([]map[string]CallResponse, len(blocks))
+	slots := make(chan struct{}, concurrency)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i, block := range blocks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, block *big.Int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results, err := caller.ExecuteAtBlock(ctx, calls, requireSuccess, BlockNumber(block))
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("block %s: %w", block, err)
+					cancel()
+				})
+				return
+			}
+			samples[i] = results
+		}(i, block)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// headerClient is a chain of head+1 blocks mined every 12 seconds from genesis,
+// with a stall of an hour before block stall.
+type headerClient struct {
+	aggregateClient
+	head  uint64
+	stall uint64
+
+	mu    sync.Mutex
+	reads int
+}
+
+func (c *headerClient) BlockHeaderAt(ctx context.Context, block BlockRef) (BlockHeader, error) {
+	c.mu.Lock()
+	c.reads++
+	c.mu.Unlock()
+	n := c.head
+	if number, ok := block.Number(); ok {
+		n = number.Uint64()
+	}
+	at := 1600000000 + 12*n
+	if n >= c.stall {
+		at += 3600
+	}
+	return BlockHeader{Number: new(big.Int).SetUint64(n), Hash: common.BigToHash(new(big.Int).SetUint64(n)), Time: at}, nil
+}
+
+func TestBlockRange(t *testing.T) {
+	assert.Equal(t, []*big.Int{big.NewInt(100), big.NewInt(1100), big.NewInt(2100)}, BlockRange(100, 2500, 1000))
+	assert.Equal(t, []*big.Int{big.NewInt(5)}, BlockRange(5, 5, 0))
+	assert.Empty(t, BlockRange(6, 5, 1))
+
+	from := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	times := DailyTimes(from, from.AddDate(0, 0, 2))
+	assert.Equal(t, []time.Time{from, from.AddDate(0, 0, 1), from.AddDate(0, 0, 2)}, times)
+}
+
+func TestBlockFinder(t *testing.T) {
+	client := &headerClient{head: 1000000, stall: 500000}
+	finder := NewBlockFinder(client)
+
+	header, err := finder.BlockAt(context.Background(), time.Unix(1600000000+12*1000+5, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), header.Number.Uint64())
+
+	// during the stall the last block before it is returned
+	header, err = finder.BlockAt(context.Background(), time.Unix(1600000000+12*500000+60, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(499999), header.Number.Uint64())
+
+	header, err = finder.BlockAt(context.Background(), time.Unix(1600000000+12*700000+3600, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(700000), header.Number.Uint64())
+	assert.Less(t, client.reads, 60)
+
+	_, err = finder.BlockAt(context.Background(), time.Unix(1500000000, 0))
+	assert.Error(t, err)
+	_, err = finder.BlockAt(context.Background(), time.Unix(1700000000, 0))
+	assert.Error(t, err)
+}
+
+func TestMultiCaller_Sample(t *testing.T) {
+	client := &aggregateClient{}
+	caller, err := NewMultiCaller(client, testMultiCallAddress)
+	assert.NoError(t, err)
+	caller.Cache = NewMemoryCache(100)
+
+	blocks := BlockRange(100, 190, 10)
+	samples, err := caller.Sample(context.Background(), cacheCalls("a", "b"), false, blocks, 4)
+	assert.NoError(t, err)
+	assert.Len(t, samples, len(blocks))
+	for _, results := range samples {
+		assert.Equal(t, []byte("b"), results["b"].ReturnData)
+	}
+	assert.Len(t, client.batches, len(blocks))
+
+	// sampled blocks are answered from the cache
+	_, err = caller.Sample(context.Background(), cacheCalls("a", "b"), false, BlockRange(100, 200, 10), 4)
+	assert.NoError(t, err)
+	assert.Len(t, client.batches, len(blocks)+1)
+
+	failing := &blockClient{err: assert.AnError}
+	caller, err = NewMultiCaller(failing, testMultiCallAddress)
+	assert.NoError(t, err)
+	_, err = caller.Sample(context.Background(), cacheCalls("a"), false, blocks, 2)
+	assert.ErrorIs(t, err, assert.AnError)
+}
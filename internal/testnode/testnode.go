@@ -0,0 +1,189 @@
+// Package testnode is a JSON-RPC server standing in for a chain node in tests.
+package testnode
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// multicallABI holds the multicall entry points the node executes.
+const multicallABI = `[
+	{"name": "aggregate", "type": "function", "stateMutability": "view",
+		"inputs": [{"name": "calls", "type": "tuple[]", "components": [{"name": "target", "type": "address"}, {"name": "callData", "type": "bytes"}]}],
+		"outputs": [{"name": "blockNumber", "type": "uint256"}, {"name": "returnData", "type": "bytes[]"}]},
+	{"name": "tryAggregate", "type": "function", "stateMutability": "view",
+		"inputs": [{"name": "requireSuccess", "type": "bool"}, {"name": "calls", "type": "tuple[]", "components": [{"name": "target", "type": "address"}, {"name": "callData", "type": "bytes"}]}],
+		"outputs": [{"name": "returnData", "type": "tuple[]", "components": [{"name": "success", "type": "bool"}, {"name": "returnData", "type": "bytes"}]}]},
+	{"name": "tryBlockAndAggregate", "type": "function", "stateMutability": "view",
+		"inputs": [{"name": "requireSuccess", "type": "bool"}, {"name": "calls", "type": "tuple[]", "components": [{"name": "target", "type": "address"}, {"name": "callData", "type": "bytes"}]}],
+		"outputs": [{"name": "blockNumber", "type": "uint256"}, {"name": "blockHash", "type": "bytes32"}, {"name": "returnData", "type": "tuple[]", "components": [{"name": "success", "type": "bool"}, {"name": "returnData", "type": "bytes"}]}]}
+]`
+
+// Error is a JSON-RPC error answered by a Handler.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Handler answers a JSON-RPC method from its params.
+type Handler func(params []json.RawMessage) (interface{}, error)
+
+// Answer executes one sub-call of a multicall.
+type Answer func(target common.Address, data []byte) (success bool, returnData []byte)
+
+// Node answers the methods it has a Handler for and "0x" to the others.
+type Node struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	requests map[string]*int32
+}
+
+func New(t *testing.T) *Node {
+	node := &Node{handlers: make(map[string]Handler), requests: make(map[string]*int32)}
+	node.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid request %s: %v", body, err)
+		}
+		node.mu.Lock()
+		handler := node.handlers[req.Method]
+		count, ok := node.requests[req.Method]
+		if !ok {
+			count = new(int32)
+			node.requests[req.Method] = count
+		}
+		node.mu.Unlock()
+		atomic.AddInt32(count, 1)
+
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": "0x"}
+		if handler != nil {
+			result, err := handler(req.Params)
+			if rpcErr, ok := err.(*Error); ok {
+				delete(res, "result")
+				res["error"] = rpcErr
+			} else if err != nil {
+				t.Errorf("%s: %v", req.Method, err)
+			} else {
+				res["result"] = result
+			}
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	return node
+}
+
+func (n *Node) Handle(method string, handler Handler) *Node {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.handlers[method] = handler
+	return n
+}
+
+// Result answers method with a fixed result.
+func (n *Node) Result(method string, result interface{}) *Node {
+	return n.Handle(method, func(params []json.RawMessage) (interface{}, error) {
+		return result, nil
+	})
+}
+
+// Requests counts the requests received for method.
+func (n *Node) Requests(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if count, ok := n.requests[method]; ok {
+		return int(atomic.LoadInt32(count))
+	}
+	return 0
+}
+
+// Multicall answers eth_call to any address as a multicall contract at
+// blockNumber, executing every sub-call with answer.
+func (n *Node) Multicall(blockNumber int64, answer Answer) *Node {
+	mcAbi, err := abi.JSON(strings.NewReader(multicallABI))
+	if err != nil {
+		panic(err)
+	}
+	return n.Handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var msg struct {
+			Data  hexutil.Bytes `json:"data"`
+			Input hexutil.Bytes `json:"input"`
+		}
+		if err := json.Unmarshal(params[0], &msg); err != nil {
+			return nil, err
+		}
+		if len(msg.Data) == 0 {
+			msg.Data = msg.Input
+		}
+		method, err := mcAbi.MethodById(msg.Data)
+		if err != nil {
+			return nil, &Error{Code: -32000, Message: "execution reverted"}
+		}
+		inputs, err := method.Inputs.Unpack(msg.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := inputs[len(inputs)-1].([]struct {
+			Target   common.Address `json:"target"`
+			CallData []byte         `json:"callData"`
+		})
+		results := make([]struct {
+			Success    bool
+			ReturnData []byte
+		}, len(calls))
+		for i, call := range calls {
+			results[i].Success, results[i].ReturnData = answer(call.Target, call.CallData)
+			requireSuccess := method.Name == "aggregate" || inputs[0] == true
+			if requireSuccess && !results[i].Success {
+				return nil, &Error{Code: -32000, Message: "execution reverted"}
+			}
+		}
+		var output []byte
+		number := new(big.Int).SetInt64(blockNumber)
+		switch method.Name {
+		case "aggregate":
+			returnData := make([][]byte, len(results))
+			for i, result := range results {
+				returnData[i] = result.ReturnData
+			}
+			output, err = method.Outputs.Pack(number, returnData)
+		case "tryAggregate":
+			output, err = method.Outputs.Pack(results)
+		default:
+			output, err = method.Outputs.Pack(number, common.Hash{}, results)
+		}
+		return hexutil.Bytes(output), err
+	})
+}
+
+// Echo answers every sub-call with its call data, except those to reverting,
+// which revert with revertData.
+func Echo(reverting common.Address, revertData []byte) Answer {
+	return func(target common.Address, data []byte) (bool, []byte) {
+		if target == reverting {
+			return false, revertData
+		}
+		return true, data
+	}
+}
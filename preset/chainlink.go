@@ -0,0 +1,97 @@
+package preset
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/depocket/multicall-go/call"
+)
+
+type PriceFeed struct {
+	Address         string
+	RoundId         *big.Int
+	Answer          *big.Int
+	StartedAt       time.Time
+	UpdatedAt       time.Time
+	AnsweredInRound *big.Int
+	Decimals        uint8
+	Price           *big.Float
+	Stale           bool
+	Success         bool
+}
+
+type Chainlink struct {
+	contract *call.Contract
+	methods  map[string]string
+	maxAge   time.Duration
+	now      func() time.Time
+}
+
+// NewChainlink creates a reader for AggregatorV3 feeds. Feeds whose last update
+// is older than maxAge are flagged as stale; a zero maxAge disables the age check.
+func NewChainlink(contract *call.Contract, maxAge time.Duration) *Chainlink {
+	return &Chainlink{
+		contract: contract,
+		methods: resolveMethods(contract,
+			"latestRoundData()(uint80,int256,uint256,uint256,uint80)",
+			"decimals()(uint8)",
+		),
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+}
+
+// Feeds reads latestRoundData and decimals of every feed in one batch and
+// returns the answers normalized by the feed decimals.
+func (c *Chainlink) Feeds(ctx context.Context, feeds []string) ([]PriceFeed, error) {
+	res := make([]PriceFeed, len(feeds))
+	if len(feeds) == 0 {
+		return res, nil
+	}
+	for i, feed := range feeds {
+		c.contract.
+			AddCall(callKey("latestRoundData", i), feed, c.methods["latestRoundData"]).
+			AddCall(callKey("decimals", i), feed, c.methods["decimals"])
+	}
+	results, err := c.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	for i, feed := range feeds {
+		res[i].Address = feed
+		decimals, okDecimals := resultUint8(results, callKey("decimals", i))
+		round := results[callKey("latestRoundData", i)]
+		if !okDecimals || !round.Success || len(round.ReturnData) != 5 {
+			continue
+		}
+		values, ok := bigInts(round.ReturnData)
+		if !ok {
+			continue
+		}
+		res[i].Decimals = decimals
+		res[i].RoundId = values[0]
+		res[i].Answer = values[1]
+		res[i].StartedAt = time.Unix(values[2].Int64(), 0)
+		res[i].UpdatedAt = time.Unix(values[3].Int64(), 0)
+		res[i].AnsweredInRound = values[4]
+		res[i].Price = scaledPrice(new(big.Rat).SetInt(res[i].Answer), 0, res[i].Decimals)
+		res[i].Stale = res[i].IsStale(c.maxAge, now)
+		res[i].Success = true
+	}
+	return res, nil
+}
+
+// IsStale reports whether the round is unusable at the given time: it was never
+// completed, it was answered in an earlier round, it has a non-positive answer
+// or it was last updated more than maxAge ago.
+func (f PriceFeed) IsStale(maxAge time.Duration, now time.Time) bool {
+	if f.Answer == nil || f.Answer.Sign() <= 0 || f.UpdatedAt.Unix() == 0 {
+		return true
+	}
+	if f.AnsweredInRound != nil && f.RoundId != nil && f.AnsweredInRound.Cmp(f.RoundId) < 0 {
+		return true
+	}
+	return maxAge > 0 && now.Sub(f.UpdatedAt) > maxAge
+}
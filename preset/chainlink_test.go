@@ -0,0 +1,86 @@
+package preset
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceFeed_IsStale(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	feed := PriceFeed{
+		RoundId:         big.NewInt(10),
+		Answer:          big.NewInt(180000000000),
+		UpdatedAt:       now.Add(-30 * time.Minute),
+		AnsweredInRound: big.NewInt(10),
+	}
+	assert.False(t, feed.IsStale(time.Hour, now))
+	assert.True(t, feed.IsStale(10*time.Minute, now))
+	assert.False(t, feed.IsStale(0, now))
+
+	feed.AnsweredInRound = big.NewInt(9)
+	assert.True(t, feed.IsStale(time.Hour, now))
+
+	feed.AnsweredInRound = big.NewInt(10)
+	feed.Answer = big.NewInt(0)
+	assert.True(t, feed.IsStale(time.Hour, now))
+}
+
+func TestScaledPrice_FeedDecimals(t *testing.T) {
+	price := scaledPrice(new(big.Rat).SetInt(big.NewInt(180012345678)), 0, 8)
+	assert.Equal(t, "1800.12345678", price.Text('f', 8))
+}
+
+var (
+	testFreshFeed = common.HexToAddress("0x0f01")
+	testStaleFeed = common.HexToAddress("0x0f02")
+)
+
+// replyFeeds serves an 8 decimals feed updated at 1700000000 and a feed last
+// updated a day earlier, and reverts every call to any other feed.
+func replyFeeds(target common.Address, method string, _ []interface{}) ([]interface{}, bool) {
+	updatedAt := map[common.Address]int64{testFreshFeed: 1700000000, testStaleFeed: 1700000000 - 86400}
+	if _, ok := updatedAt[target]; !ok {
+		return nil, false
+	}
+	switch method {
+	case "latestRoundData":
+		updated := big.NewInt(updatedAt[target])
+		return []interface{}{big.NewInt(10), big.NewInt(180012345678), updated, updated, big.NewInt(10)}, true
+	case "decimals":
+		return []interface{}{uint8(8)}, true
+	}
+	return nil, false
+}
+
+func TestChainlink_Feeds(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	chainlink := NewChainlink(contract, time.Hour)
+	chainlink.now = func() time.Time { return time.Unix(1700000600, 0) }
+	node := connectNode(t, contract, replyFeeds)
+	defer node.Close()
+
+	feeds, err := chainlink.Feeds(context.Background(), []string{testFreshFeed.Hex(), testStaleFeed.Hex(), testBroken.Hex()})
+	assert.NoError(t, err)
+	assert.Len(t, feeds, 3)
+
+	assert.True(t, feeds[0].Success)
+	assert.False(t, feeds[0].Stale)
+	assert.Equal(t, uint8(8), feeds[0].Decimals)
+	assert.Equal(t, "10", feeds[0].RoundId.String())
+	assert.Equal(t, "180012345678", feeds[0].Answer.String())
+	assert.Equal(t, time.Unix(1700000000, 0), feeds[0].UpdatedAt)
+	assert.Equal(t, "1800.12345678", feeds[0].Price.Text('f', 8))
+
+	assert.True(t, feeds[1].Success)
+	assert.True(t, feeds[1].Stale)
+
+	assert.False(t, feeds[2].Success)
+	assert.Equal(t, testBroken.Hex(), feeds[2].Address)
+	assert.Nil(t, feeds[2].Price)
+}
@@ -0,0 +1,146 @@
+package preset
+
+import (
+	"context"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/core"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type Kind string
+
+const (
+	KindNoCode  Kind = "no_code"
+	KindERC20   Kind = "erc20"
+	KindERC721  Kind = "erc721"
+	KindERC1155 Kind = "erc1155"
+	KindUnknown Kind = "unknown"
+)
+
+var (
+	interfaceERC165  = [4]byte{0x01, 0xff, 0xc9, 0xa7}
+	interfaceInvalid = [4]byte{0xff, 0xff, 0xff, 0xff}
+	interfaceERC721  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	interfaceERC1155 = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+type Detection struct {
+	Address string
+	Kind    Kind
+	ERC165  bool
+}
+
+type Detector struct {
+	contract *call.Contract
+	methods  map[string]string
+}
+
+func NewDetector(contract *call.Contract) *Detector {
+	return &Detector{
+		contract: contract,
+		methods: resolveMethods(contract,
+			"supportsInterface(bytes4)(bool)",
+			"decimals()(uint8)",
+			"totalSupply()(uint256)",
+			"balanceOf(address)(uint256)",
+		),
+	}
+}
+
+// Detect classifies every address in one batch. EIP-165 capable contracts are
+// classified by the interfaces they report; other contracts are probed for the
+// ERC20 getters. Calls to an address without code succeed with empty return
+// data inside the multicall, so the code of addresses answering every probe
+// that way is read with eth_getCode, which tells them from contracts with an
+// empty fallback.
+func (d *Detector) Detect(ctx context.Context, addresses []string) ([]Detection, error) {
+	res := make([]Detection, len(addresses))
+	if len(addresses) == 0 {
+		return res, nil
+	}
+	for i, address := range addresses {
+		d.contract.
+			AddCall(callKey("erc165", i), address, d.methods["supportsInterface"], interfaceERC165).
+			AddCall(callKey("invalid", i), address, d.methods["supportsInterface"], interfaceInvalid).
+			AddCall(callKey("erc721", i), address, d.methods["supportsInterface"], interfaceERC721).
+			AddCall(callKey("erc1155", i), address, d.methods["supportsInterface"], interfaceERC1155).
+			AddCall(callKey("decimals", i), address, d.methods["decimals"]).
+			AddCall(callKey("totalSupply", i), address, d.methods["totalSupply"]).
+			AddCall(callKey("balanceOf", i), address, d.methods["balanceOf"], common.Address{})
+	}
+	results, err := d.contract.RawCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for i, address := range addresses {
+		hasCode := true
+		if emptyProbes(results, i) {
+			code, err := d.contract.CodeAt(ctx, address)
+			if err != nil {
+				return nil, err
+			}
+			hasCode = len(code) > 0
+		}
+		res[i].Address = address
+		res[i].Kind = d.classify(results, i, hasCode)
+		res[i].ERC165 = d.supports(results, callKey("erc165", i)) && !d.supports(results, callKey("invalid", i))
+	}
+	return res, nil
+}
+
+var probes = []string{"erc165", "invalid", "erc721", "erc1155", "decimals", "totalSupply", "balanceOf"}
+
+// emptyProbes reports whether every probe of an address succeeded without
+// return data, as calls to an address without code do.
+func emptyProbes(results map[string]core.CallResponse, index int) bool {
+	for _, probe := range probes {
+		response := results[callKey(probe, index)]
+		if !response.Status || len(response.ReturnData) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Detector) classify(results map[string]core.CallResponse, index int, hasCode bool) Kind {
+	if !hasCode {
+		return KindNoCode
+	}
+	if d.supports(results, callKey("erc165", index)) && !d.supports(results, callKey("invalid", index)) {
+		if d.supports(results, callKey("erc1155", index)) {
+			return KindERC1155
+		}
+		if d.supports(results, callKey("erc721", index)) {
+			return KindERC721
+		}
+	}
+	if d.decodes(results, callKey("decimals", index)) &&
+		d.decodes(results, callKey("totalSupply", index)) &&
+		d.decodes(results, callKey("balanceOf", index)) {
+		return KindERC20
+	}
+	return KindUnknown
+}
+
+func (d *Detector) supports(results map[string]core.CallResponse, key string) bool {
+	response := results[key]
+	if !response.Status {
+		return false
+	}
+	data, err := d.contract.Abi().Unpack(response.Method, response.ReturnData)
+	if err != nil || len(data) == 0 {
+		return false
+	}
+	supported, ok := data[0].(bool)
+	return ok && supported
+}
+
+func (d *Detector) decodes(results map[string]core.CallResponse, key string) bool {
+	response := results[key]
+	if !response.Status {
+		return false
+	}
+	_, err := d.contract.Abi().Unpack(response.Method, response.ReturnData)
+	return err == nil
+}
@@ -0,0 +1,103 @@
+package preset
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/core"
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+// probeResults packs the answers of one address to the detector probes, a nil
+// answer reverting and an empty one succeeding without return data.
+func probeResults(t *testing.T, d *Detector, answers map[string]interface{}) map[string]core.CallResponse {
+	methods := map[string]string{
+		"erc165": "supportsInterface", "invalid": "supportsInterface", "erc721": "supportsInterface",
+		"erc1155": "supportsInterface", "decimals": "decimals", "totalSupply": "totalSupply", "balanceOf": "balanceOf",
+	}
+	results := make(map[string]core.CallResponse)
+	for _, probe := range probes {
+		method := d.methods[methods[probe]]
+		response := core.CallResponse{Method: method, Status: true}
+		switch answer := answers[probe].(type) {
+		case nil:
+			response.Status = false
+		case string:
+		default:
+			data, err := d.contract.Abi().Methods[method].Outputs.Pack(answer)
+			assert.NoError(t, err)
+			response.ReturnData = data
+		}
+		results[callKey(probe, 0)] = response
+	}
+	return results
+}
+
+func TestDetector_Classify(t *testing.T) {
+	detector := NewDetector(call.NewContractBuilder().Build())
+	empty := map[string]interface{}{}
+	for _, probe := range probes {
+		empty[probe] = ""
+	}
+	erc20 := map[string]interface{}{"decimals": uint8(18), "totalSupply": big.NewInt(1), "balanceOf": big.NewInt(0)}
+	erc165 := func(erc721, erc1155 bool) map[string]interface{} {
+		return map[string]interface{}{"erc165": true, "invalid": false, "erc721": erc721, "erc1155": erc1155}
+	}
+	tests := []struct {
+		name    string
+		answers map[string]interface{}
+		hasCode bool
+		want    Kind
+	}{
+		{"no code", empty, false, KindNoCode},
+		{"empty fallback", empty, true, KindUnknown},
+		{"erc721", erc165(true, false), true, KindERC721},
+		{"erc1155", erc165(false, true), true, KindERC1155},
+		{"erc1155 reporting erc721", erc165(true, true), true, KindERC1155},
+		{"erc165 without token interfaces", erc165(false, false), true, KindUnknown},
+		{"supports every interface", map[string]interface{}{"erc165": true, "invalid": true, "erc721": true}, true, KindUnknown},
+		{"erc20", erc20, true, KindERC20},
+		{"erc20 without decimals", map[string]interface{}{"totalSupply": big.NewInt(1), "balanceOf": big.NewInt(0)}, true, KindUnknown},
+		{"reverting", map[string]interface{}{}, true, KindUnknown},
+	}
+	for _, test := range tests {
+		results := probeResults(t, detector, test.answers)
+		assert.Equal(t, test.want, detector.classify(results, 0, test.hasCode), test.name)
+	}
+	assert.True(t, emptyProbes(probeResults(t, detector, empty), 0))
+	assert.False(t, emptyProbes(probeResults(t, detector, erc20), 0))
+}
+
+func TestDetector_Detect(t *testing.T) {
+	account := common.HexToAddress("0x21")
+	fallback := common.HexToAddress("0x22")
+	var codeRequests []string
+	node := testnode.New(t).
+		Multicall(100, func(target common.Address, data []byte) (bool, []byte) {
+			return true, nil
+		}).
+		Handle("eth_getCode", func(params []json.RawMessage) (interface{}, error) {
+			codeRequests = append(codeRequests, string(params[0]))
+			if strings.Contains(strings.ToLower(string(params[0])), strings.ToLower(fallback.Hex()[2:])) {
+				return "0x6080", nil
+			}
+			return "0x", nil
+		})
+	defer node.Close()
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	contract := call.NewContractBuilder().WithClient(client).Build()
+
+	detections, err := NewDetector(contract).Detect(context.Background(), []string{account.Hex(), fallback.Hex()})
+	assert.NoError(t, err)
+	assert.Equal(t, KindNoCode, detections[0].Kind)
+	assert.Equal(t, KindUnknown, detections[1].Kind)
+	assert.Len(t, codeRequests, 2)
+}
@@ -0,0 +1,113 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type ERC1155Token struct {
+	NFT
+	URI    string
+	HasURI bool
+}
+
+type ERC1155BalanceQuery struct {
+	Collection string
+	Accounts   []string
+	TokenIds   []*big.Int
+}
+
+type ERC1155Balances struct {
+	ERC1155BalanceQuery
+	Balances []*big.Int
+	Success  bool
+}
+
+type ERC1155 struct {
+	contract *call.Contract
+	methods  map[string]string
+}
+
+func NewERC1155(contract *call.Contract) *ERC1155 {
+	return &ERC1155{
+		contract: contract,
+		methods: resolveMethods(contract,
+			"balanceOf(address,uint256)(uint256)",
+			"balanceOfBatch(address[],uint256[])(uint256[])",
+			"uri(uint256)(string)",
+		),
+	}
+}
+
+// URIs reads the metadata URI of every token and substitutes the {id} placeholder.
+func (e *ERC1155) URIs(ctx context.Context, tokens []NFT) ([]ERC1155Token, error) {
+	res := make([]ERC1155Token, len(tokens))
+	if len(tokens) == 0 {
+		return res, nil
+	}
+	for i, token := range tokens {
+		e.contract.AddCall(callKey("uri", i), token.Collection, e.methods["uri"], token.TokenId)
+	}
+	results, err := e.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range tokens {
+		res[i].NFT = token
+		if uri, ok := resultString(results, callKey("uri", i)); ok {
+			res[i].URI = ExpandURI(uri, token.TokenId)
+			res[i].HasURI = true
+		}
+	}
+	return res, nil
+}
+
+// BalanceOf returns the balance of owner for each token, nil where the call reverted.
+func (e *ERC1155) BalanceOf(ctx context.Context, tokens []NFT, owner string) ([]*big.Int, error) {
+	return executeBigInts(ctx, e.contract, "balanceOf", len(tokens), func(key string, i int) {
+		e.contract.AddCall(key, tokens[i].Collection, e.methods["balanceOf"], common.HexToAddress(owner), tokens[i].TokenId)
+	})
+}
+
+// BalanceOfBatch runs one balanceOfBatch per query. A query that reverts only
+// marks its own result as unsuccessful.
+func (e *ERC1155) BalanceOfBatch(ctx context.Context, queries []ERC1155BalanceQuery) ([]ERC1155Balances, error) {
+	res := make([]ERC1155Balances, len(queries))
+	if len(queries) == 0 {
+		return res, nil
+	}
+	for i, query := range queries {
+		accounts := make([]common.Address, 0, len(query.Accounts))
+		for _, account := range query.Accounts {
+			accounts = append(accounts, common.HexToAddress(account))
+		}
+		e.contract.AddCall(callKey("balanceOfBatch", i), query.Collection, e.methods["balanceOfBatch"], accounts, query.TokenIds)
+	}
+	results, err := e.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for i, query := range queries {
+		res[i].ERC1155BalanceQuery = query
+		value, ok := resultValue(results, callKey("balanceOfBatch", i))
+		if !ok {
+			continue
+		}
+		res[i].Balances, res[i].Success = value.([]*big.Int)
+	}
+	return res, nil
+}
+
+// ExpandURI replaces the ERC1155 {id} placeholder with the lowercase hex token id
+// padded to 64 characters.
+func ExpandURI(uri string, tokenId *big.Int) string {
+	if tokenId == nil {
+		return uri
+	}
+	return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenId))
+}
@@ -0,0 +1,115 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandURI(t *testing.T) {
+	assert.Equal(
+		t,
+		"https://token-cdn-domain/000000000000000000000000000000000000000000000000000000000004cce0.json",
+		ExpandURI("https://token-cdn-domain/{id}.json", big.NewInt(314592)),
+	)
+	assert.Equal(t, "ipfs://Qm/1.json", ExpandURI("ipfs://Qm/1.json", big.NewInt(1)))
+	assert.Equal(t, "{id}", ExpandURI("{id}", nil))
+}
+
+var (
+	testCollection = common.HexToAddress("0x0c01")
+	testBurned     = common.HexToAddress("0x0c02")
+)
+
+// replyERC1155 serves testCollection, where holder one owns token id 1 and ids
+// below 10 exist, and testBurned, whose every call reverts.
+func replyERC1155(target common.Address, method string, args []interface{}) ([]interface{}, bool) {
+	if target != testCollection {
+		return nil, false
+	}
+	balance := func(account common.Address, id *big.Int) *big.Int {
+		if account == testHolderOne && id.Cmp(big.NewInt(1)) == 0 {
+			return big.NewInt(5)
+		}
+		return big.NewInt(0)
+	}
+	switch method {
+	case "uri":
+		if args[0].(*big.Int).Cmp(big.NewInt(10)) >= 0 {
+			return nil, false
+		}
+		return []interface{}{"ipfs://Qm/{id}.json"}, true
+	case "balanceOf":
+		return []interface{}{balance(args[0].(common.Address), args[1].(*big.Int))}, true
+	case "balanceOfBatch":
+		accounts, ids := args[0].([]common.Address), args[1].([]*big.Int)
+		balances := make([]*big.Int, len(accounts))
+		for i := range accounts {
+			balances[i] = balance(accounts[i], ids[i])
+		}
+		return []interface{}{balances}, true
+	}
+	return nil, false
+}
+
+func TestERC1155_URIs(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc1155 := NewERC1155(contract)
+	node := connectNode(t, contract, replyERC1155)
+	defer node.Close()
+
+	tokens, err := erc1155.URIs(context.Background(), []NFT{
+		{Collection: testCollection.Hex(), TokenId: big.NewInt(1)},
+		{Collection: testCollection.Hex(), TokenId: big.NewInt(10)},
+		{Collection: testBurned.Hex(), TokenId: big.NewInt(1)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 3)
+	assert.True(t, tokens[0].HasURI)
+	assert.Equal(t, "ipfs://Qm/0000000000000000000000000000000000000000000000000000000000000001.json", tokens[0].URI)
+	assert.Equal(t, big.NewInt(1), tokens[0].TokenId)
+	assert.False(t, tokens[1].HasURI)
+	assert.False(t, tokens[2].HasURI)
+	assert.Equal(t, testBurned.Hex(), tokens[2].Collection)
+}
+
+func TestERC1155_BalanceOf(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc1155 := NewERC1155(contract)
+	node := connectNode(t, contract, replyERC1155)
+	defer node.Close()
+
+	balances, err := erc1155.BalanceOf(context.Background(), []NFT{
+		{Collection: testCollection.Hex(), TokenId: big.NewInt(1)},
+		{Collection: testCollection.Hex(), TokenId: big.NewInt(2)},
+		{Collection: testBurned.Hex(), TokenId: big.NewInt(1)},
+	}, testHolderOne.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, "[5 0 <nil>]", fmt.Sprint(balances))
+}
+
+func TestERC1155_BalanceOfBatch(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc1155 := NewERC1155(contract)
+	node := connectNode(t, contract, replyERC1155)
+	defer node.Close()
+
+	accounts := []string{testHolderOne.Hex(), testHolderTwo.Hex(), testHolderOne.Hex()}
+	ids := []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(2)}
+	results, err := erc1155.BalanceOfBatch(context.Background(), []ERC1155BalanceQuery{
+		{Collection: testCollection.Hex(), Accounts: accounts, TokenIds: ids},
+		{Collection: testBurned.Hex(), Accounts: accounts, TokenIds: ids},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "[5 0 0]", fmt.Sprint(results[0].Balances))
+	assert.Equal(t, testCollection.Hex(), results[0].Collection)
+	assert.False(t, results[1].Success)
+	assert.Nil(t, results[1].Balances)
+}
@@ -0,0 +1,116 @@
+package preset
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type VaultPosition struct {
+	Vault         string
+	Holder        string
+	Asset         common.Address
+	AssetDecimals uint8
+	Shares        *big.Int
+	Assets        *big.Int
+	TotalAssets   *big.Int
+	Amount        *big.Float
+	Success       bool
+}
+
+type ERC4626 struct {
+	contract *call.Contract
+	methods  map[string]string
+}
+
+func NewERC4626(contract *call.Contract) *ERC4626 {
+	return &ERC4626{
+		contract: contract,
+		methods: resolveMethods(contract,
+			"asset()(address)",
+			"balanceOf(address)(uint256)",
+			"convertToAssets(uint256)(uint256)",
+			"totalAssets()(uint256)",
+			"decimals()(uint8)",
+		),
+	}
+}
+
+// Positions resolves the position of every holder in every vault. The first
+// batch reads the vault assets, totals and share balances; the second converts
+// the shares to assets and reads the decimals of each underlying asset.
+func (e *ERC4626) Positions(ctx context.Context, vaults []string, holders []string) ([]VaultPosition, error) {
+	res := make([]VaultPosition, 0, len(vaults)*len(holders))
+	for _, vault := range vaults {
+		for _, holder := range holders {
+			res = append(res, VaultPosition{Vault: vault, Holder: holder})
+		}
+	}
+	if len(res) == 0 {
+		return res, nil
+	}
+
+	for i, vault := range vaults {
+		e.contract.
+			AddCall(callKey("asset", i), vault, e.methods["asset"]).
+			AddCall(callKey("totalAssets", i), vault, e.methods["totalAssets"])
+	}
+	for i, position := range res {
+		e.contract.AddCall(callKey("balanceOf", i), position.Vault, e.methods["balanceOf"], common.HexToAddress(position.Holder))
+	}
+	results, err := e.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make([]common.Address, len(vaults))
+	totalAssets := make([]*big.Int, len(vaults))
+	assetFound := make([]bool, len(vaults))
+	decimalsKeys := make(map[common.Address]string)
+	for i := range vaults {
+		assets[i], assetFound[i] = resultAddress(results, callKey("asset", i))
+		totalAssets[i], _ = resultBigInt(results, callKey("totalAssets", i))
+		if _, ok := decimalsKeys[assets[i]]; assetFound[i] && !ok {
+			decimalsKeys[assets[i]] = callKey("decimals", len(decimalsKeys))
+			e.contract.AddCall(decimalsKeys[assets[i]], assets[i].Hex(), e.methods["decimals"])
+		}
+	}
+	for i := range res {
+		res[i].Shares, _ = resultBigInt(results, callKey("balanceOf", i))
+		if res[i].Shares != nil && res[i].Shares.Sign() > 0 {
+			e.contract.AddCall(callKey("convertToAssets", i), res[i].Vault, e.methods["convertToAssets"], res[i].Shares)
+		}
+	}
+	if len(decimalsKeys) == 0 {
+		e.contract.ClearCall()
+		return res, nil
+	}
+	results, err = e.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range res {
+		vaultIndex := i / len(holders)
+		if !assetFound[vaultIndex] || res[i].Shares == nil {
+			continue
+		}
+		decimals, ok := resultUint8(results, decimalsKeys[assets[vaultIndex]])
+		if !ok {
+			continue
+		}
+		res[i].Asset = assets[vaultIndex]
+		res[i].AssetDecimals = decimals
+		res[i].TotalAssets = totalAssets[vaultIndex]
+		if res[i].Shares.Sign() == 0 {
+			res[i].Assets = new(big.Int)
+		} else if res[i].Assets, ok = resultBigInt(results, callKey("convertToAssets", i)); !ok {
+			continue
+		}
+		res[i].Amount = scaledPrice(new(big.Rat).SetInt(res[i].Assets), 0, res[i].AssetDecimals)
+		res[i].Success = true
+	}
+	return res, nil
+}
@@ -0,0 +1,159 @@
+package preset
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testAsset     = common.HexToAddress("0xa0")
+	testVaultA    = common.HexToAddress("0x01")
+	testVaultB    = common.HexToAddress("0x02")
+	testBroken    = common.HexToAddress("0x03")
+	testHolderOne = common.HexToAddress("0x11")
+	testHolderTwo = common.HexToAddress("0x12")
+)
+
+// newVaultNode serves two vaults of testAsset, converting shares to twice as
+// many assets, and a vault whose asset call reverts. It counts decimals calls.
+func newVaultNode(t *testing.T, contract *call.Contract, decimalsCalls *int32) *testnode.Node {
+	balances := map[common.Address]map[common.Address]int64{
+		testVaultA: {testHolderOne: 100, testHolderTwo: 0},
+		testVaultB: {testHolderOne: 50, testHolderTwo: 7},
+		testBroken: {testHolderOne: 1, testHolderTwo: 1},
+	}
+	totals := map[common.Address]int64{testVaultA: 1000, testVaultB: 500, testBroken: 1}
+	return testnode.New(t).Multicall(100, func(target common.Address, data []byte) (bool, []byte) {
+		contractAbi := contract.Abi()
+		method, err := contractAbi.MethodById(data)
+		if err != nil {
+			return false, nil
+		}
+		args, err := method.Inputs.Unpack(data[4:])
+		assert.NoError(t, err)
+		var output []byte
+		switch method.RawName {
+		case "asset":
+			if target == testBroken {
+				return false, nil
+			}
+			output, err = method.Outputs.Pack(testAsset)
+		case "totalAssets":
+			output, err = method.Outputs.Pack(big.NewInt(totals[target]))
+		case "balanceOf":
+			output, err = method.Outputs.Pack(big.NewInt(balances[target][args[0].(common.Address)]))
+		case "convertToAssets":
+			output, err = method.Outputs.Pack(new(big.Int).Mul(args[0].(*big.Int), big.NewInt(2)))
+		case "decimals":
+			// packed by hand, decimals may be registered with other outputs
+			atomic.AddInt32(decimalsCalls, 1)
+			output = common.LeftPadBytes([]byte{6}, 32)
+		default:
+			return false, nil
+		}
+		assert.NoError(t, err)
+		return true, output
+	})
+}
+
+func TestERC4626_Positions(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc4626 := NewERC4626(contract)
+	var decimalsCalls int32
+	node := newVaultNode(t, contract, &decimalsCalls)
+	defer node.Close()
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	contract.WithClient(client)
+
+	vaults := []string{testVaultA.Hex(), testVaultB.Hex(), testBroken.Hex()}
+	holders := []string{testHolderOne.Hex(), testHolderTwo.Hex()}
+	positions, err := erc4626.Positions(context.Background(), vaults, holders)
+	assert.NoError(t, err)
+	assert.Len(t, positions, 6)
+	assert.Equal(t, 2, node.Requests("eth_call"))
+	// both vaults hold the same asset, whose decimals are read once
+	assert.Equal(t, int32(1), decimalsCalls)
+
+	expected := []struct {
+		vault, holder common.Address
+		shares        int64
+		assets        int64
+		total         int64
+		amount        string
+	}{
+		{testVaultA, testHolderOne, 100, 200, 1000, "0.0002"},
+		{testVaultA, testHolderTwo, 0, 0, 1000, "0"},
+		{testVaultB, testHolderOne, 50, 100, 500, "0.0001"},
+		{testVaultB, testHolderTwo, 7, 14, 500, "0.000014"},
+	}
+	for i, want := range expected {
+		position := positions[i]
+		assert.True(t, position.Success, i)
+		assert.Equal(t, want.vault.Hex(), position.Vault, i)
+		assert.Equal(t, want.holder.Hex(), position.Holder, i)
+		assert.Equal(t, testAsset, position.Asset, i)
+		assert.Equal(t, uint8(6), position.AssetDecimals, i)
+		assert.Equal(t, want.shares, position.Shares.Int64(), i)
+		assert.Equal(t, want.assets, position.Assets.Int64(), i)
+		assert.Equal(t, want.total, position.TotalAssets.Int64(), i)
+		assert.Equal(t, want.amount, position.Amount.Text('f', -1), i)
+	}
+	for _, position := range positions[4:] {
+		assert.False(t, position.Success)
+		assert.Equal(t, testBroken.Hex(), position.Vault)
+		assert.Equal(t, big.NewInt(1), position.Shares)
+		assert.Nil(t, position.Assets)
+	}
+}
+
+func TestERC4626_PositionsWithoutAssets(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc4626 := NewERC4626(contract)
+	var decimalsCalls int32
+	node := newVaultNode(t, contract, &decimalsCalls)
+	defer node.Close()
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	contract.WithClient(client)
+
+	positions, err := erc4626.Positions(context.Background(), []string{testBroken.Hex()}, []string{testHolderOne.Hex()})
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+	assert.False(t, positions[0].Success)
+	// without any asset the second batch is skipped
+	assert.Equal(t, 1, node.Requests("eth_call"))
+
+	positions, err = erc4626.Positions(context.Background(), nil, []string{testHolderOne.Hex()})
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+}
+
+func TestERC4626_PositionsSharedContract(t *testing.T) {
+	// another preset declared a balanceOf overload and decimals returns uint256
+	contract := call.NewContractBuilder().AddMethod("decimals()(uint256)")
+	NewERC1155(contract)
+	erc4626 := NewERC4626(contract)
+	assert.NotEqual(t, "decimals", erc4626.methods["decimals"])
+	var decimalsCalls int32
+	node := newVaultNode(t, contract, &decimalsCalls)
+	defer node.Close()
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	contract.WithClient(client)
+
+	positions, err := erc4626.Positions(context.Background(), []string{testVaultA.Hex()}, []string{testHolderOne.Hex()})
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+	assert.True(t, positions[0].Success)
+	assert.Equal(t, uint8(6), positions[0].AssetDecimals)
+	assert.Equal(t, int64(200), positions[0].Assets.Int64())
+}
@@ -0,0 +1,125 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxTokensOfOwner bounds the balance TokensOfOwner enumerates, since the
+// balance is read from the collection and sizes the second batch.
+const MaxTokensOfOwner = 10000
+
+type NFT struct {
+	Collection string
+	TokenId    *big.Int
+}
+
+type ERC721Token struct {
+	NFT
+	Owner    common.Address
+	Exists   bool
+	TokenURI string
+	HasURI   bool
+}
+
+type ERC721 struct {
+	contract *call.Contract
+	methods  map[string]string
+}
+
+func NewERC721(contract *call.Contract) *ERC721 {
+	return &ERC721{
+		contract: contract,
+		methods: resolveMethods(contract,
+			"ownerOf(uint256)(address)",
+			"balanceOf(address)(uint256)",
+			"tokenURI(uint256)(string)",
+			"totalSupply()(uint256)",
+			"tokenByIndex(uint256)(uint256)",
+			"tokenOfOwnerByIndex(address,uint256)(uint256)",
+		),
+	}
+}
+
+// Tokens reads owner and metadata URI of every token in one batch. A token whose
+// ownerOf or tokenURI reverts, e.g. a burned one, is returned with Exists or HasURI unset.
+func (e *ERC721) Tokens(ctx context.Context, tokens []NFT) ([]ERC721Token, error) {
+	res := make([]ERC721Token, len(tokens))
+	if len(tokens) == 0 {
+		return res, nil
+	}
+	for i, token := range tokens {
+		e.contract.
+			AddCall(callKey("ownerOf", i), token.Collection, e.methods["ownerOf"], token.TokenId).
+			AddCall(callKey("tokenURI", i), token.Collection, e.methods["tokenURI"], token.TokenId)
+	}
+	results, err := e.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range tokens {
+		res[i].NFT = token
+		res[i].Owner, res[i].Exists = resultAddress(results, callKey("ownerOf", i))
+		res[i].TokenURI, res[i].HasURI = resultString(results, callKey("tokenURI", i))
+	}
+	return res, nil
+}
+
+// BalanceOf returns the balance of owner in each collection, nil where the call reverted.
+func (e *ERC721) BalanceOf(ctx context.Context, collections []string, owner string) ([]*big.Int, error) {
+	return executeBigInts(ctx, e.contract, "balanceOf", len(collections), func(key string, i int) {
+		e.contract.AddCall(key, collections[i], e.methods["balanceOf"], common.HexToAddress(owner))
+	})
+}
+
+func (e *ERC721) TotalSupply(ctx context.Context, collections []string) ([]*big.Int, error) {
+	return executeBigInts(ctx, e.contract, "totalSupply", len(collections), func(key string, i int) {
+		e.contract.AddCall(key, collections[i], e.methods["totalSupply"])
+	})
+}
+
+func (e *ERC721) TokenByIndex(ctx context.Context, collection string, indexes []*big.Int) ([]*big.Int, error) {
+	return executeBigInts(ctx, e.contract, "tokenByIndex", len(indexes), func(key string, i int) {
+		e.contract.AddCall(key, collection, e.methods["tokenByIndex"], indexes[i])
+	})
+}
+
+func (e *ERC721) TokenOfOwnerByIndex(ctx context.Context, collection string, owner string, indexes []*big.Int) ([]*big.Int, error) {
+	return executeBigInts(ctx, e.contract, "tokenOfOwnerByIndex", len(indexes), func(key string, i int) {
+		e.contract.AddCall(key, collection, e.methods["tokenOfOwnerByIndex"], common.HexToAddress(owner), indexes[i])
+	})
+}
+
+// TokensOfOwner enumerates every token id held by owner in an ERC721Enumerable
+// collection, reading the balance first and then all indexes in a second batch.
+func (e *ERC721) TokensOfOwner(ctx context.Context, collection string, owner string) ([]*big.Int, error) {
+	balances, err := e.BalanceOf(ctx, []string{collection}, owner)
+	if err != nil {
+		return nil, err
+	}
+	if balances[0] == nil {
+		return nil, nil
+	}
+	if balances[0].Cmp(big.NewInt(MaxTokensOfOwner)) > 0 {
+		return nil, fmt.Errorf("balance of %s in %s is %s, more than %d tokens", owner, collection, balances[0], MaxTokensOfOwner)
+	}
+	indexes := make([]*big.Int, balances[0].Int64())
+	for i := range indexes {
+		indexes[i] = big.NewInt(int64(i))
+	}
+	tokenIds, err := e.TokenOfOwnerByIndex(ctx, collection, owner, indexes)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*big.Int, 0, len(tokenIds))
+	for _, tokenId := range tokenIds {
+		if tokenId != nil {
+			res = append(res, tokenId)
+		}
+	}
+	return res, nil
+}
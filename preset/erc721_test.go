@@ -0,0 +1,94 @@
+package preset
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// replyERC721 serves testCollection, an enumerable collection where holder one
+// owns tokens 7 and 9 and token 8 is burned, and testBurned, whose every call
+// reverts.
+func replyERC721(target common.Address, method string, args []interface{}) ([]interface{}, bool) {
+	if target != testCollection {
+		return nil, false
+	}
+	owned := []*big.Int{big.NewInt(7), big.NewInt(9)}
+	switch method {
+	case "ownerOf", "tokenURI":
+		id := args[0].(*big.Int).Int64()
+		if id != 7 && id != 9 {
+			return nil, false
+		}
+		if method == "ownerOf" {
+			return []interface{}{testHolderOne}, true
+		}
+		return []interface{}{"ipfs://Qm/" + args[0].(*big.Int).String()}, true
+	case "balanceOf":
+		if args[0].(common.Address) != testHolderOne {
+			return []interface{}{big.NewInt(0)}, true
+		}
+		return []interface{}{big.NewInt(int64(len(owned)))}, true
+	case "tokenOfOwnerByIndex":
+		index := args[1].(*big.Int).Int64()
+		if args[0].(common.Address) != testHolderOne || index >= int64(len(owned)) {
+			return nil, false
+		}
+		return []interface{}{owned[index]}, true
+	}
+	return nil, false
+}
+
+func TestERC721_Tokens(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc721 := NewERC721(contract)
+	node := connectNode(t, contract, replyERC721)
+	defer node.Close()
+
+	tokens, err := erc721.Tokens(context.Background(), []NFT{
+		{Collection: testCollection.Hex(), TokenId: big.NewInt(7)},
+		{Collection: testCollection.Hex(), TokenId: big.NewInt(8)},
+		{Collection: testBurned.Hex(), TokenId: big.NewInt(7)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 3)
+	assert.True(t, tokens[0].Exists)
+	assert.Equal(t, testHolderOne, tokens[0].Owner)
+	assert.True(t, tokens[0].HasURI)
+	assert.Equal(t, "ipfs://Qm/7", tokens[0].TokenURI)
+	assert.Equal(t, big.NewInt(8), tokens[1].TokenId)
+	assert.False(t, tokens[1].Exists)
+	assert.False(t, tokens[1].HasURI)
+	assert.False(t, tokens[2].Exists)
+	assert.False(t, tokens[2].HasURI)
+}
+
+func TestERC721_BalanceOf(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc721 := NewERC721(contract)
+	node := connectNode(t, contract, replyERC721)
+	defer node.Close()
+
+	balances, err := erc721.BalanceOf(context.Background(), []string{testCollection.Hex(), testBurned.Hex()}, testHolderOne.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{big.NewInt(2), nil}, balances)
+}
+
+func TestERC721_TokensOfOwner(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	erc721 := NewERC721(contract)
+	node := connectNode(t, contract, replyERC721)
+	defer node.Close()
+
+	tokenIds, err := erc721.TokensOfOwner(context.Background(), testCollection.Hex(), testHolderOne.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{big.NewInt(7), big.NewInt(9)}, tokenIds)
+
+	tokenIds, err = erc721.TokensOfOwner(context.Background(), testBurned.Hex(), testHolderOne.Hex())
+	assert.NoError(t, err)
+	assert.Nil(t, tokenIds)
+}
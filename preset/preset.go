@@ -0,0 +1,102 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// resolveMethods adds the signatures a preset needs to contract, skipping those
+// already there, e.g. added by another preset, and maps every method name to
+// the name AddCall takes.
+func resolveMethods(contract *call.Contract, signatures ...string) map[string]string {
+	methods := make(map[string]string, len(signatures))
+	for _, signature := range signatures {
+		methods[call.MethodName(signature)] = contract.ResolveMethod(signature)
+	}
+	return methods
+}
+
+func callKey(method string, index int) string {
+	return fmt.Sprintf("%s:%d", method, index)
+}
+
+func resultValue(results map[string]call.Result, key string) (interface{}, bool) {
+	result, ok := results[key]
+	if !ok || !result.Success || len(result.ReturnData) == 0 {
+		return nil, false
+	}
+	return result.ReturnData[0], true
+}
+
+func resultBigInt(results map[string]call.Result, key string) (*big.Int, bool) {
+	value, ok := resultValue(results, key)
+	if !ok {
+		return nil, false
+	}
+	number, ok := value.(*big.Int)
+	return number, ok
+}
+
+func resultUint8(results map[string]call.Result, key string) (uint8, bool) {
+	value, ok := resultValue(results, key)
+	if !ok {
+		return 0, false
+	}
+	number, ok := value.(uint8)
+	return number, ok
+}
+
+// bigInts converts the decoded values of a tuple that are all *big.Int.
+func bigInts(values []interface{}) ([]*big.Int, bool) {
+	numbers := make([]*big.Int, len(values))
+	for i, value := range values {
+		number, ok := value.(*big.Int)
+		if !ok {
+			return nil, false
+		}
+		numbers[i] = number
+	}
+	return numbers, true
+}
+
+func resultAddress(results map[string]call.Result, key string) (common.Address, bool) {
+	value, ok := resultValue(results, key)
+	if !ok {
+		return common.Address{}, false
+	}
+	address, ok := value.(common.Address)
+	return address, ok
+}
+
+func resultString(results map[string]call.Result, key string) (string, bool) {
+	value, ok := resultValue(results, key)
+	if !ok {
+		return "", false
+	}
+	text, ok := value.(string)
+	return text, ok
+}
+
+func executeBigInts(ctx context.Context, contract *call.Contract, method string, count int, add func(key string, index int)) ([]*big.Int, error) {
+	values := make([]*big.Int, count)
+	if count == 0 {
+		return values, nil
+	}
+	for i := 0; i < count; i++ {
+		add(callKey(method, i), i)
+	}
+	results, err := contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range values {
+		if value, ok := resultBigInt(results, callKey(method, i)); ok {
+			values[i] = value
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,57 @@
+package preset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+// reply answers a decoded sub-call with its outputs, or reverts it when ok is
+// false.
+type reply func(target common.Address, method string, args []interface{}) (outputs []interface{}, ok bool)
+
+// connectNode starts a node executing the sub-calls of contract with reply,
+// keyed by method name without the overload suffix, and points contract at it.
+func connectNode(t *testing.T, contract *call.Contract, reply reply) *testnode.Node {
+	node := testnode.New(t).Multicall(100, func(target common.Address, data []byte) (bool, []byte) {
+		contractAbi := contract.Abi()
+		method, err := contractAbi.MethodById(data)
+		if err != nil {
+			return false, nil
+		}
+		args, err := method.Inputs.Unpack(data[4:])
+		assert.NoError(t, err)
+		outputs, ok := reply(target, method.RawName, args)
+		if !ok {
+			return false, nil
+		}
+		output, err := method.Outputs.Pack(outputs...)
+		assert.NoError(t, err)
+		return true, output
+	})
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	contract.WithClient(client)
+	return node
+}
+
+func TestPresets_ShareContract(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	var erc1155 *ERC1155
+	assert.NotPanics(t, func() {
+		NewERC721(contract)
+		erc1155 = NewERC1155(contract)
+		NewERC4626(contract)
+		NewChainlink(contract, time.Hour)
+		NewDetector(contract)
+		NewUniswap(contract)
+	})
+	assert.Equal(t, "balanceOf0", erc1155.methods["balanceOf"])
+	assert.Equal(t, "balanceOf(address,uint256)", contract.Abi().Methods["balanceOf0"].Sig)
+	assert.Equal(t, "balanceOf", NewERC4626(contract).methods["balanceOf"])
+}
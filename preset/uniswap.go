@@ -0,0 +1,199 @@
+package preset
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const pricePrecision = 256
+
+var q192 = new(big.Int).Lsh(big.NewInt(1), 192)
+
+type V2Pool struct {
+	Address            string
+	Token0             common.Address
+	Token1             common.Address
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	BlockTimestampLast uint32
+	Success            bool
+}
+
+type V3Pool struct {
+	Address                    string
+	Token0                     common.Address
+	Token1                     common.Address
+	SqrtPriceX96               *big.Int
+	Tick                       int32
+	ObservationIndex           uint16
+	ObservationCardinality     uint16
+	ObservationCardinalityNext uint16
+	FeeProtocol                uint8
+	Unlocked                   bool
+	Liquidity                  *big.Int
+	Fee                        uint32
+	Success                    bool
+}
+
+type Uniswap struct {
+	contract *call.Contract
+	methods  map[string]string
+}
+
+func NewUniswap(contract *call.Contract) *Uniswap {
+	return &Uniswap{
+		contract: contract,
+		methods: resolveMethods(contract,
+			"token0()(address)",
+			"token1()(address)",
+			"getReserves()(uint112,uint112,uint32)",
+			"slot0()(uint160,int24,uint16,uint16,uint16,uint8,bool)",
+			"liquidity()(uint128)",
+			"fee()(uint24)",
+		),
+	}
+}
+
+func (u *Uniswap) V2Pools(ctx context.Context, pools []string) ([]V2Pool, error) {
+	v2Pools, _, err := u.Pools(ctx, pools, nil)
+	return v2Pools, err
+}
+
+func (u *Uniswap) V3Pools(ctx context.Context, pools []string) ([]V3Pool, error) {
+	_, v3Pools, err := u.Pools(ctx, nil, pools)
+	return v3Pools, err
+}
+
+// Pools reads the state of V2-style and V3-style pools in a single batch. A pool
+// is marked unsuccessful when any of its calls reverted.
+func (u *Uniswap) Pools(ctx context.Context, v2 []string, v3 []string) ([]V2Pool, []V3Pool, error) {
+	v2Pools := make([]V2Pool, len(v2))
+	v3Pools := make([]V3Pool, len(v3))
+	if len(v2)+len(v3) == 0 {
+		return v2Pools, v3Pools, nil
+	}
+	for i, pool := range v2 {
+		u.contract.
+			AddCall(callKey("v2.token0", i), pool, u.methods["token0"]).
+			AddCall(callKey("v2.token1", i), pool, u.methods["token1"]).
+			AddCall(callKey("v2.getReserves", i), pool, u.methods["getReserves"])
+	}
+	for i, pool := range v3 {
+		u.contract.
+			AddCall(callKey("v3.token0", i), pool, u.methods["token0"]).
+			AddCall(callKey("v3.token1", i), pool, u.methods["token1"]).
+			AddCall(callKey("v3.slot0", i), pool, u.methods["slot0"]).
+			AddCall(callKey("v3.liquidity", i), pool, u.methods["liquidity"]).
+			AddCall(callKey("v3.fee", i), pool, u.methods["fee"])
+	}
+	results, err := u.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, pool := range v2 {
+		state := V2Pool{Address: pool}
+		var ok0, ok1 bool
+		state.Token0, ok0 = resultAddress(results, callKey("v2.token0", i))
+		state.Token1, ok1 = resultAddress(results, callKey("v2.token1", i))
+		reserves := results[callKey("v2.getReserves", i)]
+		if reserves.Success && len(reserves.ReturnData) == 3 {
+			values, okReserves := bigInts(reserves.ReturnData[:2])
+			timestamp, okTimestamp := reserves.ReturnData[2].(uint32)
+			if okReserves && okTimestamp {
+				state.Reserve0, state.Reserve1 = values[0], values[1]
+				state.BlockTimestampLast = timestamp
+				state.Success = ok0 && ok1
+			}
+		}
+		v2Pools[i] = state
+	}
+
+	for i, pool := range v3 {
+		state := V3Pool{Address: pool}
+		var ok0, ok1, okLiquidity bool
+		state.Token0, ok0 = resultAddress(results, callKey("v3.token0", i))
+		state.Token1, ok1 = resultAddress(results, callKey("v3.token1", i))
+		state.Liquidity, okLiquidity = resultBigInt(results, callKey("v3.liquidity", i))
+		fee, okFee := resultBigInt(results, callKey("v3.fee", i))
+		if okFee {
+			state.Fee = uint32(fee.Uint64())
+		}
+		slot0 := results[callKey("v3.slot0", i)]
+		if slot0.Success && len(slot0.ReturnData) == 7 {
+			values, okValues := bigInts(slot0.ReturnData[:2])
+			index, okIndex := slot0.ReturnData[2].(uint16)
+			cardinality, okCardinality := slot0.ReturnData[3].(uint16)
+			cardinalityNext, okCardinalityNext := slot0.ReturnData[4].(uint16)
+			feeProtocol, okFeeProtocol := slot0.ReturnData[5].(uint8)
+			unlocked, okUnlocked := slot0.ReturnData[6].(bool)
+			if okValues && okIndex && okCardinality && okCardinalityNext && okFeeProtocol && okUnlocked {
+				state.SqrtPriceX96 = values[0]
+				state.Tick = int32(values[1].Int64())
+				state.ObservationIndex = index
+				state.ObservationCardinality = cardinality
+				state.ObservationCardinalityNext = cardinalityNext
+				state.FeeProtocol = feeProtocol
+				state.Unlocked = unlocked
+				state.Success = ok0 && ok1 && okLiquidity && okFee
+			}
+		}
+		v3Pools[i] = state
+	}
+	return v2Pools, v3Pools, nil
+}
+
+// Price0 returns the spot price of token0 denominated in token1, adjusted for
+// the decimals of both tokens. It is nil for an unsuccessful or empty pool.
+func (p V2Pool) Price0(decimals0, decimals1 uint8) *big.Float {
+	if !p.Success || p.Reserve0.Sign() == 0 {
+		return nil
+	}
+	return scaledPrice(new(big.Rat).SetFrac(p.Reserve1, p.Reserve0), decimals0, decimals1)
+}
+
+func (p V2Pool) Price1(decimals0, decimals1 uint8) *big.Float {
+	if !p.Success || p.Reserve1.Sign() == 0 {
+		return nil
+	}
+	return scaledPrice(new(big.Rat).SetFrac(p.Reserve0, p.Reserve1), decimals1, decimals0)
+}
+
+// Price0 returns the spot price of token0 denominated in token1 derived from
+// sqrtPriceX96, adjusted for the decimals of both tokens.
+func (p V3Pool) Price0(decimals0, decimals1 uint8) *big.Float {
+	if !p.Success || p.SqrtPriceX96.Sign() == 0 {
+		return nil
+	}
+	priceX192 := new(big.Int).Mul(p.SqrtPriceX96, p.SqrtPriceX96)
+	return scaledPrice(new(big.Rat).SetFrac(priceX192, q192), decimals0, decimals1)
+}
+
+func (p V3Pool) Price1(decimals0, decimals1 uint8) *big.Float {
+	if !p.Success || p.SqrtPriceX96.Sign() == 0 {
+		return nil
+	}
+	priceX192 := new(big.Int).Mul(p.SqrtPriceX96, p.SqrtPriceX96)
+	return scaledPrice(new(big.Rat).SetFrac(q192, priceX192), decimals1, decimals0)
+}
+
+func scaledPrice(price *big.Rat, baseDecimals, quoteDecimals uint8) *big.Float {
+	exponent := int64(baseDecimals) - int64(quoteDecimals)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(exponent)), nil)
+	if exponent >= 0 {
+		price.Mul(price, new(big.Rat).SetInt(scale))
+	} else {
+		price.Quo(price, new(big.Rat).SetInt(scale))
+	}
+	return new(big.Float).SetPrec(pricePrecision).SetRat(price)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
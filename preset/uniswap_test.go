@@ -0,0 +1,99 @@
+package preset
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV2Pool_Price(t *testing.T) {
+	// 1 WETH (18 decimals) against 2000 USDC (6 decimals).
+	pool := V2Pool{
+		Reserve0: new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18)),
+		Reserve1: big.NewInt(20000 * 1e6),
+		Success:  true,
+	}
+	assert.Equal(t, "2000", pool.Price0(18, 6).Text('f', 0))
+	assert.Equal(t, "0.0005", pool.Price1(18, 6).Text('f', 4))
+	assert.Nil(t, V2Pool{}.Price0(18, 6))
+}
+
+func TestV3Pool_Price(t *testing.T) {
+	// sqrtPriceX96 of 2^96 is a raw price of exactly 1.
+	pool := V3Pool{
+		SqrtPriceX96: new(big.Int).Lsh(big.NewInt(1), 96),
+		Success:      true,
+	}
+	assert.Equal(t, "1", pool.Price0(18, 18).Text('f', 0))
+	assert.Equal(t, "1000000000000", pool.Price0(18, 6).Text('f', 0))
+	assert.Equal(t, "0.000000000001", pool.Price1(18, 6).Text('f', 12))
+}
+
+var (
+	testV2Pool       = common.HexToAddress("0x0b02")
+	testV3Pool       = common.HexToAddress("0x0b03")
+	testToken0       = common.HexToAddress("0x0a00")
+	testToken1       = common.HexToAddress("0x0a01")
+	testSqrtPriceX96 = new(big.Int).Lsh(big.NewInt(1), 96)
+)
+
+// replyPools serves testV2Pool and testV3Pool, and reverts every call to any
+// other pool.
+func replyPools(target common.Address, method string, _ []interface{}) ([]interface{}, bool) {
+	switch method {
+	case "token0":
+		return []interface{}{testToken0}, target == testV2Pool || target == testV3Pool
+	case "token1":
+		return []interface{}{testToken1}, target == testV2Pool || target == testV3Pool
+	case "getReserves":
+		return []interface{}{big.NewInt(1000), big.NewInt(2000), uint32(1600000000)}, target == testV2Pool
+	case "slot0":
+		return []interface{}{testSqrtPriceX96, big.NewInt(-887), uint16(3), uint16(10), uint16(20), uint8(4), true}, target == testV3Pool
+	case "liquidity":
+		return []interface{}{big.NewInt(123456)}, target == testV3Pool
+	case "fee":
+		return []interface{}{big.NewInt(3000)}, target == testV3Pool
+	}
+	return nil, false
+}
+
+func TestUniswap_Pools(t *testing.T) {
+	contract := call.NewContractBuilder().Build()
+	uniswap := NewUniswap(contract)
+	node := connectNode(t, contract, replyPools)
+	defer node.Close()
+
+	v2, v3, err := uniswap.Pools(context.Background(),
+		[]string{testV2Pool.Hex(), testBroken.Hex()},
+		[]string{testV3Pool.Hex(), testBroken.Hex()},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, v2, 2)
+	assert.Len(t, v3, 2)
+
+	assert.True(t, v2[0].Success)
+	assert.Equal(t, testToken0, v2[0].Token0)
+	assert.Equal(t, testToken1, v2[0].Token1)
+	assert.Equal(t, "1000", v2[0].Reserve0.String())
+	assert.Equal(t, "2000", v2[0].Reserve1.String())
+	assert.Equal(t, uint32(1600000000), v2[0].BlockTimestampLast)
+	assert.False(t, v2[1].Success)
+	assert.Equal(t, testBroken.Hex(), v2[1].Address)
+
+	assert.True(t, v3[0].Success)
+	assert.Equal(t, testToken0, v3[0].Token0)
+	assert.Equal(t, 0, testSqrtPriceX96.Cmp(v3[0].SqrtPriceX96))
+	assert.Equal(t, int32(-887), v3[0].Tick)
+	assert.Equal(t, uint16(3), v3[0].ObservationIndex)
+	assert.Equal(t, uint16(10), v3[0].ObservationCardinality)
+	assert.Equal(t, uint16(20), v3[0].ObservationCardinalityNext)
+	assert.Equal(t, uint8(4), v3[0].FeeProtocol)
+	assert.True(t, v3[0].Unlocked)
+	assert.Equal(t, "123456", v3[0].Liquidity.String())
+	assert.Equal(t, uint32(3000), v3[0].Fee)
+	assert.False(t, v3[1].Success)
+}
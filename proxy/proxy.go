@@ -0,0 +1,262 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/depocket/multicall-go/core"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const maxBodyBytes = 16 << 20
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type callArgs struct {
+	From     *common.Address `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+}
+
+// Proxy is a JSON-RPC endpoint that answers plain eth_call requests through a
+// Batcher and forwards every other request to the upstream node unchanged.
+type Proxy struct {
+	upstream   string
+	batcher    *core.Batcher
+	httpClient *http.Client
+}
+
+func New(upstream string, batcher *core.Batcher) *Proxy {
+	return &Proxy{
+		upstream:   upstream,
+		batcher:    batcher,
+		httpClient: http.DefaultClient,
+	}
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	isBatch := strings.HasPrefix(strings.TrimSpace(string(body)), "[")
+	var requests []request
+	if isBatch {
+		err = json.Unmarshal(body, &requests)
+	} else {
+		requests = make([]request, 1)
+		err = json.Unmarshal(body, &requests[0])
+	}
+	if err != nil {
+		writeJSON(w, response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: -32700, Message: "parse error"}})
+		return
+	}
+	if !p.coalescable(requests) {
+		p.forwardBody(w, r.Context(), body)
+		return
+	}
+
+	responses := make([]json.RawMessage, len(requests))
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = p.handle(r.Context(), requests[i])
+		}(i)
+	}
+	wg.Wait()
+	if isBatch {
+		writeJSON(w, responses)
+	} else {
+		writeJSON(w, responses[0])
+	}
+}
+
+func (p *Proxy) coalescable(requests []request) bool {
+	for _, req := range requests {
+		if _, _, _, ok := parseCall(req); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Proxy) handle(ctx context.Context, req request) json.RawMessage {
+	target, data, block, ok := parseCall(req)
+	if !ok {
+		message, _ := json.Marshal(req)
+		res, err := p.forward(ctx, message)
+		if err != nil {
+			return encode(errorResponse(req.ID, -32603, err.Error(), nil))
+		}
+		return res
+	}
+
+	result, err := p.batcher.CallAt(ctx, target, data, block)
+	if err != nil {
+		return encode(errorResponse(req.ID, -32603, err.Error(), nil))
+	}
+	if !result.Status {
+		return encode(revertResponse(req.ID, result.ReturnData))
+	}
+	value, _ := json.Marshal(hexutil.Bytes(result.ReturnData))
+	return encode(response{JSONRPC: "2.0", ID: req.ID, Result: value})
+}
+
+// parseCall accepts eth_call requests that behave the same inside a multicall:
+// no sender, value or state override, at a block tag, a block number or an
+// EIP-1898 block object.
+func parseCall(req request) (common.Address, []byte, core.BlockRef, bool) {
+	if req.Method != "eth_call" {
+		return common.Address{}, nil, core.BlockRef{}, false
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) == 0 || len(params) > 2 {
+		return common.Address{}, nil, core.BlockRef{}, false
+	}
+	var args callArgs
+	if err := json.Unmarshal(params[0], &args); err != nil || args.To == nil {
+		return common.Address{}, nil, core.BlockRef{}, false
+	}
+	if (args.From != nil && *args.From != (common.Address{})) || (args.Value != nil && args.Value.ToInt().Sign() != 0) {
+		return common.Address{}, nil, core.BlockRef{}, false
+	}
+	data := args.Input
+	if data == nil {
+		data = args.Data
+	}
+	if data == nil {
+		data = &hexutil.Bytes{}
+	}
+
+	block := core.LatestBlock
+	if len(params) == 2 {
+		var ok bool
+		if block, ok = parseBlock(params[1]); !ok {
+			return common.Address{}, nil, core.BlockRef{}, false
+		}
+	}
+	return *args.To, *data, block, true
+}
+
+func parseBlock(param json.RawMessage) (core.BlockRef, bool) {
+	var object struct {
+		BlockNumber      *hexutil.Big `json:"blockNumber"`
+		BlockHash        *common.Hash `json:"blockHash"`
+		RequireCanonical bool         `json:"requireCanonical"`
+	}
+	if err := json.Unmarshal(param, &object); err == nil {
+		switch {
+		case object.BlockHash != nil && object.BlockNumber == nil:
+			return core.BlockHash(*object.BlockHash, object.RequireCanonical), true
+		case object.BlockNumber != nil && object.BlockHash == nil:
+			return core.BlockNumber(object.BlockNumber.ToInt()), true
+		}
+		return core.BlockRef{}, false
+	}
+	var tag string
+	if err := json.Unmarshal(param, &tag); err != nil {
+		return core.BlockRef{}, false
+	}
+	switch tag {
+	case "latest", "pending", "safe", "finalized":
+		block, err := core.ParseBlockRef(tag)
+		return block, err == nil
+	case "earliest":
+		return core.BlockNumber(new(big.Int)), true
+	}
+	number, err := hexutil.DecodeBig(tag)
+	if err != nil {
+		return core.BlockRef{}, false
+	}
+	return core.BlockNumber(number), true
+}
+
+func (p *Proxy) forwardBody(w http.ResponseWriter, ctx context.Context, body []byte) {
+	res, err := p.forward(ctx, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, res)
+}
+
+func (p *Proxy) forward(ctx context.Context, body []byte) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.upstream, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("upstream answered %s", res.Status)
+	}
+	return data, nil
+}
+
+func revertResponse(id json.RawMessage, data []byte) response {
+	if len(data) == 0 {
+		return errorResponse(id, -32000, "execution reverted", nil)
+	}
+	return errorResponse(id, 3, (&core.RevertError{Data: data}).Error(), hexutil.Bytes(data))
+}
+
+func errorResponse(id json.RawMessage, code int, message string, data interface{}) response {
+	return response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message, Data: data}}
+}
+
+func encode(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/depocket/multicall-go/core"
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	multiCallAddress = common.HexToAddress("0x5BA1e12693Dc8F9c48aAD8770482f4739bEeD696")
+	revertingTarget  = common.HexToAddress("0x000000000000000000000000000000000000dead")
+	// Error("nope")
+	revertData = hexutil.MustDecode("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6e6f706500000000000000000000000000000000000000000000000000000000")
+)
+
+func TestProxy_Coalesce(t *testing.T) {
+	node := testnode.New(t).
+		Multicall(100, testnode.Echo(revertingTarget, revertData)).
+		Result("eth_chainId", "0x1")
+	defer node.Close()
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	caller, err := core.NewMultiCaller(client, multiCallAddress)
+	assert.NoError(t, err)
+	proxy := httptest.NewServer(New(node.URL, core.NewBatcher(caller, 20*time.Millisecond, 100)))
+	defer proxy.Close()
+
+	res, err := http.Post(proxy.URL, "application/json", strings.NewReader(`[
+		{"jsonrpc": "2.0", "id": 1, "method": "eth_call", "params": [{"to": "0x0000000000000000000000000000000000000001", "data": "0x1234"}, "latest"]},
+		{"jsonrpc": "2.0", "id": 2, "method": "eth_call", "params": [{"to": "0x000000000000000000000000000000000000dead", "data": "0xabcd"}]},
+		{"jsonrpc": "2.0", "id": 3, "method": "eth_chainId", "params": []}
+	]`))
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	var responses []response
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&responses))
+
+	assert.Len(t, responses, 3)
+	assert.Equal(t, `"0x1234"`, string(responses[0].Result))
+	assert.Equal(t, 3, responses[1].Error.Code)
+	assert.Equal(t, "execution reverted: nope", responses[1].Error.Message)
+	assert.Equal(t, `"0x1"`, string(responses[2].Result))
+	assert.Equal(t, 1, node.Requests("eth_call"))
+}
+
+func TestParseCall(t *testing.T) {
+	_, _, block, ok := parseCall(request{Method: "eth_call", Params: json.RawMessage(`[{"to": "0x0000000000000000000000000000000000000001"}, "0x10"]`)})
+	assert.True(t, ok)
+	assert.Equal(t, "16", block.String())
+
+	_, _, _, ok = parseCall(request{Method: "eth_call", Params: json.RawMessage(`[{"to": "0x0000000000000000000000000000000000000001", "from": "0x0000000000000000000000000000000000000002"}]`)})
+	assert.False(t, ok)
+
+	_, _, block, ok = parseCall(request{Method: "eth_call", Params: json.RawMessage(`[{"to": "0x0000000000000000000000000000000000000001"}, "safe"]`)})
+	assert.True(t, ok)
+	assert.Equal(t, core.SafeBlock, block)
+
+	_, _, block, ok = parseCall(request{Method: "eth_call", Params: json.RawMessage(`[{"to": "0x0000000000000000000000000000000000000001"},
+		{"blockHash": "0x00000000000000000000000000000000000000000000000000000000000000aa", "requireCanonical": true}]`)})
+	assert.True(t, ok)
+	assert.Equal(t, core.BlockHash(common.HexToHash("0xaa"), true), block)
+
+	_, _, _, ok = parseCall(request{Method: "eth_call", Params: json.RawMessage(`[{"to": "0x0000000000000000000000000000000000000001"}, "soon"]`)})
+	assert.False(t, ok)
+
+	_, _, _, ok = parseCall(request{Method: "eth_getBalance", Params: json.RawMessage(`["0x0000000000000000000000000000000000000001", "latest"]`)})
+	assert.False(t, ok)
+}
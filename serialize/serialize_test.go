@@ -0,0 +1,67 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var testResults = map[string]call.Result{
+	"balance": {
+		Success: true,
+		ReturnData: []interface{}{
+			new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil),
+			uint8(18),
+		},
+	},
+	"reward": {
+		Success: true,
+		ReturnData: []interface{}{
+			[]struct {
+				Output0component0 common.Address `json:"output0component0"`
+				Output0component1 *big.Int       `json:"output0component1"`
+			}{{common.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7"), big.NewInt(7)}},
+			[4]byte{0x01, 0xff, 0xc9, 0xa7},
+		},
+	},
+	"reverted": {Success: false},
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteJSON(&buffer, testResults))
+
+	var compact bytes.Buffer
+	assert.NoError(t, json.Compact(&compact, buffer.Bytes()))
+	assert.Equal(
+		t,
+		`{"balance":{"success":true,"return_data":["1000000000000000000000000000000",18]},`+
+			`"reverted":{"success":false,"return_data":[]},`+
+			`"reward":{"success":true,"return_data":[[{"output0component0":"0xdAC17F958D2ee523a2206206994597C13D831ec7","output0component1":"7"}],"0x01ffc9a7"]}}`,
+		compact.String(),
+	)
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteNDJSON(&buffer, map[string]call.Result{"reverted": testResults["reverted"]}))
+	assert.Equal(t, "{\"key\":\"reverted\",\"success\":false,\"return_data\":[]}\n", buffer.String())
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteCSV(&buffer, testResults))
+	assert.Equal(
+		t,
+		"key,success,output0,output1\n"+
+			"balance,true,1000000000000000000000000000000,18\n"+
+			"reverted,false,,\n"+
+			"reward,true,\"[{\"\"output0component0\"\":\"\"0xdAC17F958D2ee523a2206206994597C13D831ec7\"\",\"\"output0component1\"\":\"\"7\"\"}]\",0x01ffc9a7\n",
+		buffer.String(),
+	)
+}
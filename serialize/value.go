@@ -0,0 +1,127 @@
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Field is a named member of an Object.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Object is a JSON object that keeps the order of its fields, used for tuples
+// so their members appear in ABI order.
+type Object []Field
+
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// Value converts a value decoded by the ABI unpacker into its canonical form:
+// big and 64-bit integers become decimal strings, addresses are checksummed,
+// byte slices and fixed byte arrays become 0x-prefixed hex and tuples become
+// Objects keyed by their ABI names.
+func Value(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case *big.Int:
+		if value == nil {
+			return nil
+		}
+		return value.String()
+	case common.Address:
+		return value.Hex()
+	case common.Hash:
+		return value.Hex()
+	case []byte:
+		return hexutil.Encode(value)
+	case string, bool:
+		return value
+	}
+	return reflectValue(reflect.ValueOf(v))
+}
+
+func reflectValue(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return Value(value.Elem().Interface())
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return value.Int()
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return value.Uint()
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(data), value)
+			return hexutil.Encode(data)
+		}
+		return reflectSlice(value)
+	case reflect.Slice:
+		if value.IsNil() {
+			return []interface{}{}
+		}
+		return reflectSlice(value)
+	case reflect.Struct:
+		object := make(Object, 0, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			object = append(object, Field{Name: fieldName(field), Value: Value(value.Field(i).Interface())})
+		}
+		return object
+	}
+	return value.Interface()
+}
+
+func reflectSlice(value reflect.Value) []interface{} {
+	items := make([]interface{}, value.Len())
+	for i := range items {
+		items[i] = Value(value.Index(i).Interface())
+	}
+	return items
+}
+
+func fieldName(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+		return tag
+	}
+	return field.Name
+}
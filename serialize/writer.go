@@ -0,0 +1,135 @@
+package serialize
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/depocket/multicall-go/call"
+)
+
+type Record struct {
+	Key          string        `json:"key"`
+	Success      bool          `json:"success"`
+	ReturnData   []interface{} `json:"return_data"`
+	RevertReason string        `json:"revert_reason,omitempty"`
+}
+
+type result struct {
+	Success      bool          `json:"success"`
+	ReturnData   []interface{} `json:"return_data"`
+	RevertReason string        `json:"revert_reason,omitempty"`
+}
+
+// FromCall wraps the results of Contract.Call, which only returns successful calls.
+func FromCall(results map[string][]interface{}) map[string]call.Result {
+	res := make(map[string]call.Result, len(results))
+	for key, data := range results {
+		res[key] = call.Result{Success: true, ReturnData: data}
+	}
+	return res
+}
+
+// Records converts results into canonical records sorted by key.
+func Records(results map[string]call.Result) []Record {
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	records := make([]Record, 0, len(keys))
+	for _, key := range keys {
+		records = append(records, Record{
+			Key:          key,
+			Success:      results[key].Success,
+			ReturnData:   canonicalData(results[key].ReturnData),
+			RevertReason: results[key].RevertReason,
+		})
+	}
+	return records
+}
+
+// WriteJSON writes results as one JSON object keyed by call key.
+func WriteJSON(w io.Writer, results map[string]call.Result) error {
+	out := make(map[string]result, len(results))
+	for _, record := range Records(results) {
+		out[record.Key] = result{Success: record.Success, ReturnData: record.ReturnData, RevertReason: record.RevertReason}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// WriteNDJSON writes one JSON record per line.
+func WriteNDJSON(w io.Writer, results map[string]call.Result) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range Records(results) {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes one row per call with a column per output. Scalar outputs are
+// written as their canonical string, arrays and tuples as compact JSON.
+func WriteCSV(w io.Writer, results map[string]call.Result) error {
+	records := Records(results)
+	outputs := 0
+	for _, record := range records {
+		if len(record.ReturnData) > outputs {
+			outputs = len(record.ReturnData)
+		}
+	}
+	writer := csv.NewWriter(w)
+	header := []string{"key", "success"}
+	for i := 0; i < outputs; i++ {
+		header = append(header, fmt.Sprintf("output%d", i))
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := make([]string, len(header))
+		row[0] = record.Key
+		row[1] = fmt.Sprint(record.Success)
+		for i, value := range record.ReturnData {
+			cell, err := csvCell(value)
+			if err != nil {
+				return err
+			}
+			row[i+2] = cell
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func canonicalData(data []interface{}) []interface{} {
+	if data == nil {
+		return []interface{}{}
+	}
+	res := make([]interface{}, len(data))
+	for i, value := range data {
+		res[i] = Value(value)
+	}
+	return res
+}
+
+func csvCell(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case Object, []interface{}:
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/depocket/multicall-go/core"
+	"github.com/depocket/multicall-go/serialize"
+	"github.com/depocket/multicall-go/spec"
+)
+
+const maxBodyBytes = 4 << 20
+
+// Options of the batch handler. Requests may only name the rpc of a batch
+// spec when it is listed in AllowedRpcs, and never its multicall address, so
+// clients cannot make the server dial hosts or read contracts of their choosing
+// nor fill its endpoint pool, limiters and health tracker with new URLs.
+type Options struct {
+	MaxCalls    int
+	Timeout     time.Duration
+	AllowedRpcs []string
+}
+
+// Response lists the result of every call sorted by key. Error is set when the
+// batch could not run, or when calls that do not allow failure reverted.
+type Response struct {
+	Results []serialize.Record `json:"results,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+type handler struct {
+	options Options
+}
+
+// NewHandler serves batch requests on POST /v1/batch. The request body is a
+// JSON batch spec as read by spec.ParseJSON. GET /v1/endpoints reports the
+// health of the RPC endpoints used so far.
+func NewHandler(options Options) http.Handler {
+	h := &handler{options: options}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/batch", h.batch)
+	mux.HandleFunc("/v1/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(core.DefaultHealth.Snapshot())
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+func (h *handler) batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeResponse(w, http.StatusMethodNotAllowed, Response{Error: "only POST is allowed"})
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeResponse(w, http.StatusRequestEntityTooLarge, Response{Error: err.Error()})
+		return
+	}
+	batch, err := spec.ParseJSON(data)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+	if err := h.checkEndpoint(batch); err != nil {
+		writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+	if h.options.MaxCalls > 0 && len(batch.Calls) > h.options.MaxCalls {
+		err := fmt.Sprintf("batch has %d calls, the limit is %d", len(batch.Calls), h.options.MaxCalls)
+		writeResponse(w, http.StatusBadRequest, Response{Error: err})
+		return
+	}
+	contract, err := batch.Contract()
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+
+	ctx := core.WithPriority(r.Context(), core.PriorityInteractive)
+	if h.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.options.Timeout)
+		defer cancel()
+	}
+	results, err := batch.Run(ctx, contract)
+	var failed *spec.CallFailedError
+	switch {
+	case errors.As(err, &failed):
+		writeResponse(w, http.StatusOK, Response{Results: serialize.Records(results), Error: err.Error()})
+	case errors.Is(err, context.DeadlineExceeded):
+		writeResponse(w, http.StatusGatewayTimeout, Response{Error: err.Error()})
+	case err != nil:
+		writeResponse(w, http.StatusBadGateway, Response{Error: err.Error()})
+	default:
+		writeResponse(w, http.StatusOK, Response{Results: serialize.Records(results)})
+	}
+}
+
+func (h *handler) checkEndpoint(batch *spec.Spec) error {
+	if batch.MultiCall != "" {
+		return errors.New("multicall cannot be set in requests, use a chain")
+	}
+	if batch.Rpc == "" {
+		return nil
+	}
+	for _, allowed := range h.options.AllowedRpcs {
+		if batch.Rpc == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("rpc %q is not allowed, use a chain", batch.Rpc)
+}
+
+func writeResponse(w http.ResponseWriter, status int, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
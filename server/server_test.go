@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/depocket/multicall-go/core"
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func postBatch(handler http.Handler, body string) (*httptest.ResponseRecorder, Response) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/batch", strings.NewReader(body)))
+	var response Response
+	_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+	return recorder, response
+}
+
+func TestHandler_Validation(t *testing.T) {
+	handler := NewHandler(Options{MaxCalls: 1})
+
+	recorder, response := postBatch(handler, `{"chain": "ethereum"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "spec declares no methods", response.Error)
+
+	recorder, response = postBatch(handler, `{"chain": "ethereum", "methods": ["totalSupply()(uint256)"], "calls": [
+		{"key": "a", "target": "0x01", "method": "totalSupply"},
+		{"key": "b", "target": "0x02", "method": "totalSupply"}
+	]}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "batch has 2 calls, the limit is 1", response.Error)
+
+	recorder, response = postBatch(handler, `{"chain": "ethereum", "methods": ["balanceOf(address)(uint256)"], "calls": [
+		{"key": "a", "target": "0x01", "method": "balanceOf", "args": ["nope"]}
+	]}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, `call a: balanceOf argument 0 (address): invalid address "nope"`, response.Error)
+}
+
+func TestHandler_Endpoint(t *testing.T) {
+	node := testnode.New(t).Multicall(100, func(target common.Address, data []byte) (bool, []byte) {
+		return true, common.LeftPadBytes([]byte{42}, 32)
+	})
+	defer node.Close()
+	handler := NewHandler(Options{AllowedRpcs: []string{node.URL}})
+	methods := `"methods": ["totalSupply()(uint256)"], "calls": [{"key": "a", "target": "0x01", "method": "totalSupply"}]`
+
+	recorder, response := postBatch(handler, `{"chain": "ethereum", "rpc": "http://127.0.0.1:8545", `+methods+`}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, `rpc "http://127.0.0.1:8545" is not allowed, use a chain`, response.Error)
+
+	recorder, response = postBatch(handler, `{"chain": "ethereum", "multicall": "0x02", `+methods+`}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "multicall cannot be set in requests, use a chain", response.Error)
+
+	recorder, response = postBatch(handler, `{"chain": "ethereum", "rpc": "`+node.URL+`", `+methods+`}`)
+	assert.Equal(t, http.StatusOK, recorder.Code, response.Error)
+	assert.Len(t, response.Results, 1)
+	assert.Equal(t, 1, node.Requests("eth_call"))
+}
+
+func TestHandler_RevertReason(t *testing.T) {
+	reverting := common.HexToAddress("0x02")
+	revertData := append(common.FromHex("0x08c379a0"), common.LeftPadBytes([]byte{0x20}, 32)...)
+	revertData = append(revertData, common.LeftPadBytes([]byte{6}, 32)...)
+	revertData = append(revertData, common.RightPadBytes([]byte("paused"), 32)...)
+	node := testnode.New(t).Multicall(100, func(target common.Address, data []byte) (bool, []byte) {
+		if target == reverting {
+			return false, revertData
+		}
+		return true, common.LeftPadBytes([]byte{42}, 32)
+	})
+	defer node.Close()
+	handler := NewHandler(Options{AllowedRpcs: []string{node.URL}})
+
+	recorder, response := postBatch(handler, `{"chain": "ethereum", "rpc": "`+node.URL+`", "methods": ["totalSupply()(uint256)"], "calls": [
+		{"key": "a", "target": "0x01", "method": "totalSupply"},
+		{"key": "b", "target": "0x02", "method": "totalSupply", "allowFailure": true}
+	]}`)
+	assert.Equal(t, http.StatusOK, recorder.Code, response.Error)
+	assert.Len(t, response.Results, 2)
+	assert.True(t, response.Results[0].Success)
+	assert.False(t, response.Results[1].Success)
+	assert.Equal(t, "paused", response.Results[1].RevertReason)
+	assert.Contains(t, recorder.Body.String(), `"revert_reason":"paused"`)
+}
+
+func TestHandler_Method(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/batch", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestHandler_Endpoints(t *testing.T) {
+	core.DefaultHealth.Record("https://rpc.example.org", time.Millisecond, nil)
+	recorder := httptest.NewRecorder()
+	NewHandler(Options{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/endpoints", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"url":"https://rpc.example.org","state":"closed"`)
+}
@@ -0,0 +1,181 @@
+package spec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/core"
+	"gopkg.in/yaml.v3"
+)
+
+type Call struct {
+	Key          string        `json:"key" yaml:"key"`
+	Target       string        `json:"target" yaml:"target"`
+	Method       string        `json:"method" yaml:"method"`
+	Args         []interface{} `json:"args,omitempty" yaml:"args,omitempty"`
+	AllowFailure bool          `json:"allowFailure,omitempty" yaml:"allowFailure,omitempty"`
+}
+
+// Spec declares a repeatable batch of calls. Block is a block tag, number or
+// hash, the latest block by default.
+type Spec struct {
+	Chain     string        `json:"chain" yaml:"chain"`
+	Rpc       string        `json:"rpc,omitempty" yaml:"rpc,omitempty"`
+	MultiCall string        `json:"multicall,omitempty" yaml:"multicall,omitempty"`
+	Block     core.BlockRef `json:"block,omitempty" yaml:"block,omitempty"`
+	Methods   []string      `json:"methods" yaml:"methods"`
+	Calls     []Call        `json:"calls" yaml:"calls"`
+	Output    string        `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+type CallFailedError struct {
+	Keys []string
+}
+
+func (e *CallFailedError) Error() string {
+	return fmt.Sprintf("calls not allowed to fail reverted: %s", strings.Join(e.Keys, ", "))
+}
+
+// Load reads a spec from a .json, .yaml or .yml file.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseJSON(data)
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	}
+	return nil, fmt.Errorf("unsupported spec file extension %q", filepath.Ext(path))
+}
+
+func ParseJSON(data []byte) (*Spec, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	spec := &Spec{}
+	if err := decoder.Decode(spec); err != nil {
+		return nil, err
+	}
+	return spec, spec.Validate()
+}
+
+func ParseYAML(data []byte) (*Spec, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	spec := &Spec{}
+	if err := decoder.Decode(spec); err != nil {
+		return nil, err
+	}
+	return spec, spec.Validate()
+}
+
+func (s *Spec) Validate() error {
+	if s.Chain == "" && s.Rpc == "" {
+		return errors.New("spec needs a chain or an rpc")
+	}
+	if s.Rpc != "" && s.MultiCall == "" {
+		if _, ok := call.DefaultRegistry.Get(call.Chain(s.Chain)); !ok {
+			return errors.New("spec with a custom rpc needs a multicall address")
+		}
+	}
+	if len(s.Methods) == 0 {
+		return errors.New("spec declares no methods")
+	}
+	if len(s.Calls) == 0 {
+		return errors.New("spec declares no calls")
+	}
+	keys := make(map[string]bool, len(s.Calls))
+	for i, c := range s.Calls {
+		if c.Key == "" || c.Target == "" || c.Method == "" {
+			return fmt.Errorf("call %d needs a key, a target and a method", i)
+		}
+		if keys[c.Key] {
+			return fmt.Errorf("duplicate call key %q", c.Key)
+		}
+		keys[c.Key] = true
+	}
+	return nil
+}
+
+func (s *Spec) ChainConfig() (call.ChainConfig, error) {
+	config, ok := call.DefaultRegistry.Get(call.Chain(s.Chain))
+	if !ok && s.Rpc == "" {
+		return call.ChainConfig{}, fmt.Errorf("unknown chain %q", s.Chain)
+	}
+	if s.Rpc != "" {
+		config.Url, config.Urls = s.Rpc, nil
+	}
+	if s.MultiCall != "" {
+		config.MultiCallAddress = s.MultiCall
+	}
+	return config, nil
+}
+
+// BlockNumber returns the block number the spec reads at, nil for a block tag
+// or hash.
+func (s *Spec) BlockNumber() *big.Int {
+	number, _ := s.Block.Number()
+	return number
+}
+
+// Contract builds a contract holding every method and call of the spec.
+func (s *Spec) Contract() (contract *call.Contract, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	config, err := s.ChainConfig()
+	if err != nil {
+		return nil, err
+	}
+	contract = call.NewContractBuilder().WithChainConfig(config)
+	for _, method := range s.Methods {
+		contract.AddMethod(method)
+	}
+	for _, c := range s.Calls {
+		if _, err := contract.AddCoercedCall(c.Key, c.Target, c.Method, c.Args...); err != nil {
+			return nil, fmt.Errorf("call %s: %w", c.Key, err)
+		}
+	}
+	return contract, nil
+}
+
+// Execute runs the spec in one multicall. Failures of calls that do not allow
+// failure are reported as a CallFailedError together with all results.
+func (s *Spec) Execute(ctx context.Context) (map[string]call.Result, error) {
+	contract, err := s.Contract()
+	if err != nil {
+		return nil, err
+	}
+	return s.Run(ctx, contract)
+}
+
+// Run executes a contract built by Contract.
+func (s *Spec) Run(ctx context.Context, contract *call.Contract) (map[string]call.Result, error) {
+	results, err := contract.FlexibleCallAtBlock(ctx, false, s.Block)
+	if err != nil {
+		return nil, err
+	}
+	var failed []string
+	for _, c := range s.Calls {
+		if !c.AllowFailure && !results[c.Key].Success {
+			failed = append(failed, c.Key)
+		}
+	}
+	if len(failed) > 0 {
+		return results, &CallFailedError{Keys: failed}
+	}
+	return results, nil
+}
@@ -0,0 +1,72 @@
+package spec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseYAML(t *testing.T) {
+	spec, err := ParseYAML([]byte(`
+chain: ethereum
+block: 15000000
+methods:
+  - totalSupply()(uint256)
+  - balanceOf(address)(uint256)
+calls:
+  - key: usdt
+    target: "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+    method: totalSupply
+  - key: holder
+    target: "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+    method: balanceOf
+    args: ["0x5754284f345afc66a98fbB0a0Afe71e0F007B949"]
+    allowFailure: true
+output: csv
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "ethereum", spec.Chain)
+	assert.Equal(t, "15000000", spec.BlockNumber().String())
+	assert.Len(t, spec.Calls, 2)
+	assert.True(t, spec.Calls[1].AllowFailure)
+	assert.Equal(t, "csv", spec.Output)
+}
+
+func TestParseJSON(t *testing.T) {
+	spec, err := ParseJSON([]byte(`{
+		"chain": "ethereum",
+		"methods": ["balanceOf(address)(uint256)"],
+		"calls": [{"key": "a", "target": "0x01", "method": "balanceOf", "args": [12]}]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "12", fmt.Sprint(spec.Calls[0].Args[0]))
+	assert.Nil(t, spec.BlockNumber())
+}
+
+func TestSpec_Validate(t *testing.T) {
+	_, err := ParseJSON([]byte(`{"chain": "ethereum", "methods": ["totalSupply()(uint256)"], "calls": [
+		{"key": "a", "target": "0x01", "method": "totalSupply"},
+		{"key": "a", "target": "0x02", "method": "totalSupply"}
+	]}`))
+	assert.EqualError(t, err, `duplicate call key "a"`)
+
+	_, err = ParseJSON([]byte(`{"rpc": "http://localhost:8545", "methods": ["totalSupply()(uint256)"], "calls": [
+		{"key": "a", "target": "0x01", "method": "totalSupply"}
+	]}`))
+	assert.EqualError(t, err, "spec with a custom rpc needs a multicall address")
+
+	_, err = ParseYAML([]byte("chain: ethereum\nunknown: true\n"))
+	assert.Error(t, err)
+}
+
+func TestSpec_ChainConfig(t *testing.T) {
+	config, err := (&Spec{Chain: "ethereum"}).ChainConfig()
+	assert.NoError(t, err)
+	assert.Greater(t, len(config.Endpoints()), 1)
+
+	// an explicit rpc replaces every registry endpoint
+	config, err = (&Spec{Chain: "ethereum", Rpc: "http://127.0.0.1:8545"}).ChainConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.1:8545"}, config.Endpoints())
+}
@@ -0,0 +1,178 @@
+package units
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimal is an exact fixed-point number: an integer value scaled down by
+// 10^decimals. The zero value is 0.
+type Decimal struct {
+	value    *big.Int
+	decimals uint8
+}
+
+func New(value *big.Int, decimals uint8) Decimal {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return Decimal{value: new(big.Int).Set(value), decimals: decimals}
+}
+
+// NewFromString parses a decimal string keeping all of its fractional digits.
+func NewFromString(value string) (Decimal, error) {
+	_, fraction, _, err := splitDecimal(value)
+	if err != nil {
+		return Decimal{}, err
+	}
+	if len(fraction) > 255 {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrTooPrecise, value)
+	}
+	decimals := uint8(len(fraction))
+	integer, err := ParseUnits(value, decimals)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{value: integer, decimals: decimals}, nil
+}
+
+func MustNewFromString(value string) Decimal {
+	d, err := NewFromString(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) Value() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.value)
+}
+
+func (d Decimal) Decimals() uint8 {
+	return d.decimals
+}
+
+// Rescale changes the number of decimals, truncating toward zero when it decreases.
+func (d Decimal) Rescale(decimals uint8) Decimal {
+	value := d.Value()
+	if decimals >= d.decimals {
+		value.Mul(value, pow10(decimals-d.decimals))
+	} else {
+		value.Quo(value, pow10(d.decimals-decimals))
+	}
+	return Decimal{value: value, decimals: decimals}
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, decimals := align(d, o)
+	return Decimal{value: a.Add(a, b), decimals: decimals}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, decimals := align(d, o)
+	return Decimal{value: a.Sub(a, b), decimals: decimals}
+}
+
+// Mul multiplies exactly and truncates the product to the larger number of
+// decimals of both operands.
+func (d Decimal) Mul(o Decimal) Decimal {
+	product := Decimal{value: new(big.Int).Mul(d.Value(), o.Value()), decimals: d.decimals}
+	decimals := maxDecimals(d.decimals, o.decimals)
+	product.value.Mul(product.value, pow10(decimals-d.decimals))
+	product.value.Quo(product.value, pow10(o.decimals))
+	product.decimals = decimals
+	return product
+}
+
+// Quo divides d by o and truncates the quotient to the given decimals. It
+// panics when o is zero, like big.Int.
+func (d Decimal) Quo(o Decimal, decimals uint8) Decimal {
+	numerator := d.Value()
+	numerator.Mul(numerator, pow10(decimals))
+	numerator.Mul(numerator, pow10(o.decimals))
+	denominator := o.Value()
+	denominator.Mul(denominator, pow10(d.decimals))
+	return Decimal{value: numerator.Quo(numerator, denominator), decimals: decimals}
+}
+
+func (d Decimal) Neg() Decimal {
+	value := d.Value()
+	return Decimal{value: value.Neg(value), decimals: d.decimals}
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Sign() int {
+	return d.Value().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+func (d Decimal) Float() *big.Float {
+	return new(big.Float).SetPrec(256).SetRat(d.Rat())
+}
+
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Value(), pow10(d.decimals))
+}
+
+func (d Decimal) String() string {
+	return FormatUnits(d.Value(), d.decimals)
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := NewFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalJSON encodes the decimal as a string so no JSON consumer rounds it.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts both JSON strings and JSON numbers. A JSON null leaves
+// the decimal unchanged, as for other Go values.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	text := strings.Trim(string(data), `"`)
+	if strings.ContainsAny(text, "eE") {
+		return fmt.Errorf("%w: exponent notation %s", ErrInvalidNumber, text)
+	}
+	return d.UnmarshalText([]byte(text))
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, uint8) {
+	decimals := maxDecimals(a.decimals, b.decimals)
+	return a.Rescale(decimals).value, b.Rescale(decimals).value, decimals
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func maxDecimals(a, b uint8) uint8 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,104 @@
+package units
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const decimalsMethod = "decimals"
+
+// Normalize combines an amount result and a decimals result of the same batch
+// into a Decimal.
+func Normalize(results map[string]call.Result, amountKey string, decimalsKey string) (Decimal, error) {
+	amount, err := resultValue(results, amountKey)
+	if err != nil {
+		return Decimal{}, err
+	}
+	value, ok := amount.(*big.Int)
+	if !ok {
+		return Decimal{}, fmt.Errorf("result %s is %T, not an integer amount", amountKey, amount)
+	}
+	rawDecimals, err := resultValue(results, decimalsKey)
+	if err != nil {
+		return Decimal{}, err
+	}
+	decimals, ok := rawDecimals.(uint8)
+	if !ok {
+		return Decimal{}, fmt.Errorf("result %s is %T, not uint8 decimals", decimalsKey, rawDecimals)
+	}
+	return New(value, decimals), nil
+}
+
+// Normalizer adds the decimals call of every token an amount is read from to
+// the same batch, and normalizes the amounts once the batch is executed.
+type Normalizer struct {
+	contract     *call.Contract
+	decimals     string
+	amounts      map[string]string
+	decimalsKeys map[common.Address]string
+}
+
+func NewNormalizer(contract *call.Contract) *Normalizer {
+	return &Normalizer{
+		contract:     contract,
+		decimals:     contract.ResolveMethod("decimals()(uint8)"),
+		amounts:      make(map[string]string),
+		decimalsKeys: make(map[common.Address]string),
+	}
+}
+
+// AddCall adds a call whose first output is an amount of token.
+func (n *Normalizer) AddCall(callName string, token string, method string, args ...interface{}) *Normalizer {
+	n.contract.AddCall(callName, token, method, args...)
+	address := common.HexToAddress(token)
+	decimalsKey, ok := n.decimalsKeys[address]
+	if !ok {
+		decimalsKey = fmt.Sprintf("%s:%s", decimalsMethod, address.Hex())
+		n.decimalsKeys[address] = decimalsKey
+		n.contract.AddCall(decimalsKey, token, n.decimals)
+	}
+	n.amounts[callName] = decimalsKey
+	return n
+}
+
+// FlexibleCall executes the batch and returns the results of the added calls
+// with the amounts of every successful call and decimals pair.
+func (n *Normalizer) FlexibleCall(ctx context.Context) (map[string]call.Result, map[string]Decimal, error) {
+	defer n.reset()
+	results, err := n.contract.FlexibleCall(ctx, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	amounts := make(map[string]Decimal)
+	for amountKey, decimalsKey := range n.amounts {
+		if amount, err := Normalize(results, amountKey, decimalsKey); err == nil {
+			amounts[amountKey] = amount
+		}
+	}
+	for _, decimalsKey := range n.decimalsKeys {
+		if _, ok := n.amounts[decimalsKey]; !ok {
+			delete(results, decimalsKey)
+		}
+	}
+	return results, amounts, nil
+}
+
+func (n *Normalizer) reset() {
+	n.amounts = make(map[string]string)
+	n.decimalsKeys = make(map[common.Address]string)
+}
+
+func resultValue(results map[string]call.Result, key string) (interface{}, error) {
+	result, ok := results[key]
+	if !ok {
+		return nil, fmt.Errorf("no result for %s", key)
+	}
+	if !result.Success || len(result.ReturnData) == 0 {
+		return nil, fmt.Errorf("call %s failed", key)
+	}
+	return result.ReturnData[0], nil
+}
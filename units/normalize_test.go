@@ -0,0 +1,73 @@
+package units
+
+import (
+	"context"
+	"testing"
+
+	"github.com/depocket/multicall-go/call"
+	"github.com/depocket/multicall-go/internal/testnode"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testUSDC   = common.HexToAddress("0x0a06")
+	testWETH   = common.HexToAddress("0x0a12")
+	testBroken = common.HexToAddress("0x0a00")
+	testHolder = common.HexToAddress("0x11")
+)
+
+// newTokenNode serves balanceOf and decimals of a 6 and an 18 decimals token,
+// and reverts every call to any other token.
+func newTokenNode(t *testing.T, contract *call.Contract) *testnode.Node {
+	decimals := map[common.Address]byte{testUSDC: 6, testWETH: 18}
+	return testnode.New(t).Multicall(100, func(target common.Address, data []byte) (bool, []byte) {
+		tokenDecimals, ok := decimals[target]
+		if !ok {
+			return false, nil
+		}
+		contractAbi := contract.Abi()
+		method, err := contractAbi.MethodById(data)
+		if err != nil {
+			return false, nil
+		}
+		switch method.RawName {
+		case "balanceOf":
+			balance, err := ParseUnits("1.5", tokenDecimals)
+			assert.NoError(t, err)
+			return true, common.LeftPadBytes(balance.Bytes(), 32)
+		case "decimals":
+			return true, common.LeftPadBytes([]byte{tokenDecimals}, 32)
+		}
+		return false, nil
+	})
+}
+
+func TestNormalizer_FlexibleCall(t *testing.T) {
+	// decimals with other outputs does not satisfy the normalizer
+	contract := call.NewContractBuilder().
+		AddMethod("balanceOf(address)(uint256)").
+		AddMethod("decimals()(uint256)")
+	normalizer := NewNormalizer(contract)
+	node := newTokenNode(t, contract)
+	defer node.Close()
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	contract.WithClient(client)
+
+	results, amounts, err := normalizer.
+		AddCall("usdc", testUSDC.Hex(), "balanceOf", testHolder).
+		AddCall("weth", testWETH.Hex(), "balanceOf", testHolder).
+		AddCall("weth-again", testWETH.Hex(), "balanceOf", testHolder).
+		AddCall("broken", testBroken.Hex(), "balanceOf", testHolder).
+		FlexibleCall(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+	assert.False(t, results["broken"].Success)
+	assert.Len(t, amounts, 3)
+	assert.Equal(t, "1.5", amounts["usdc"].String())
+	assert.Equal(t, uint8(6), amounts["usdc"].Decimals())
+	assert.Equal(t, "1.5", amounts["weth"].String())
+	assert.Equal(t, uint8(18), amounts["weth-again"].Decimals())
+}
@@ -0,0 +1,83 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrInvalidNumber = errors.New("invalid decimal number")
+	ErrTooPrecise    = errors.New("value has more fractional digits than decimals")
+)
+
+// ParseUnits converts a human readable decimal string such as "1.5" into its
+// integer representation with the given number of decimals, e.g. 1500000 for 6.
+func ParseUnits(value string, decimals uint8) (*big.Int, error) {
+	integer, fraction, negative, err := splitDecimal(value)
+	if err != nil {
+		return nil, err
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > int(decimals) {
+		return nil, fmt.Errorf("%w: %s with %d decimals", ErrTooPrecise, value, decimals)
+	}
+	digits := integer + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	result, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidNumber, value)
+	}
+	if negative {
+		result.Neg(result)
+	}
+	return result, nil
+}
+
+// FormatUnits renders an integer amount with the given number of decimals
+// without losing precision. Trailing fractional zeros are trimmed.
+func FormatUnits(value *big.Int, decimals uint8) string {
+	if value == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(value).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	split := len(digits) - int(decimals)
+	result := digits[:split]
+	if fraction := strings.TrimRight(digits[split:], "0"); fraction != "" {
+		result += "." + fraction
+	}
+	if value.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+func splitDecimal(value string) (string, string, bool, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	if negative || strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+	parts := strings.Split(value, ".")
+	if len(parts) > 2 || (parts[0] == "" && (len(parts) == 1 || parts[1] == "")) {
+		return "", "", false, fmt.Errorf("%w: %q", ErrInvalidNumber, value)
+	}
+	for _, part := range parts {
+		for _, char := range part {
+			if char < '0' || char > '9' {
+				return "", "", false, fmt.Errorf("%w: %q", ErrInvalidNumber, value)
+			}
+		}
+	}
+	integer := parts[0]
+	if integer == "" {
+		integer = "0"
+	}
+	if len(parts) == 1 {
+		return integer, "", negative, nil
+	}
+	return integer, parts[1], negative, nil
+}
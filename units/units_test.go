@@ -0,0 +1,85 @@
+package units
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUnits(t *testing.T) {
+	value, err := ParseUnits("1.5", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, "1500000", value.String())
+
+	value, err = ParseUnits("-0.00000001", 8)
+	assert.NoError(t, err)
+	assert.Equal(t, "-1", value.String())
+
+	value, err = ParseUnits("123456789012345678901234567890.123456789012345678", 18)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789012345678901234567890123456789012345678", value.String())
+
+	value, err = ParseUnits("2.50", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "25", value.String())
+
+	_, err = ParseUnits("1.123", 2)
+	assert.ErrorIs(t, err, ErrTooPrecise)
+	_, err = ParseUnits("1e18", 18)
+	assert.ErrorIs(t, err, ErrInvalidNumber)
+	_, err = ParseUnits(".", 18)
+	assert.ErrorIs(t, err, ErrInvalidNumber)
+	for _, value := range []string{"-+1", "+-1", "--1", "++1"} {
+		_, err = ParseUnits(value, 18)
+		assert.ErrorIs(t, err, ErrInvalidNumber, value)
+	}
+	value, err = ParseUnits("+1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value.String())
+}
+
+func TestFormatUnits(t *testing.T) {
+	assert.Equal(t, "1.5", FormatUnits(big.NewInt(1500000), 6))
+	assert.Equal(t, "0.000001", FormatUnits(big.NewInt(1), 6))
+	assert.Equal(t, "-0.000001", FormatUnits(big.NewInt(-1), 6))
+	assert.Equal(t, "21000000", FormatUnits(big.NewInt(2100000000000000), 8))
+	assert.Equal(t, "42", FormatUnits(big.NewInt(42), 0))
+	assert.Equal(t, "0", FormatUnits(nil, 18))
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a := MustNewFromString("1.25")
+	b := New(big.NewInt(5), 3)
+
+	assert.Equal(t, "1.255", a.Add(b).String())
+	assert.Equal(t, "1.245", a.Sub(b).String())
+	assert.Equal(t, "0.006", a.Mul(b).String())
+	assert.Equal(t, "250", a.Quo(b, 2).String())
+	assert.Equal(t, "0.333333", MustNewFromString("1").Quo(MustNewFromString("3"), 6).String())
+	assert.Equal(t, 1, a.Cmp(b))
+	assert.Equal(t, 0, MustNewFromString("1.50").Cmp(MustNewFromString("1.5")))
+	assert.Equal(t, "-1.25", a.Neg().String())
+	assert.Equal(t, "1.2", a.Rescale(1).String())
+	assert.True(t, Decimal{}.IsZero())
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount Decimal `json:"amount"`
+	}{New(big.NewInt(123456789), 8)})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":"1.23456789"}`, string(data))
+
+	var decoded struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"a":"0.1","b":12.5}`), &decoded))
+	assert.Equal(t, "0.1", decoded.A.String())
+	assert.Equal(t, "12.5", decoded.B.String())
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"a":null}`), &decoded))
+	assert.Equal(t, "0.1", decoded.A.String())
+}
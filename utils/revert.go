@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// RevertReason describes the return data of a reverted call: the message of an
+// Error(string), the code of a Panic(uint256), or the raw data of a custom error.
+func RevertReason(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if len(data) == 36 && bytes.Equal(data[:4], panicSelector) {
+		return fmt.Sprintf("panic: 0x%x", new(big.Int).SetBytes(data[4:]))
+	}
+	return hexutil.Encode(data)
+}